@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"github.com/weaveworks/pctl/pkg/subscription"
+)
+
+const (
+	cascadeBackground = "background"
+	cascadeOrphan     = "orphan"
+)
+
+func deleteCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "delete",
+		Usage:     "delete a profile Subscription and the resources it created from the cluster",
+		UsageText: "pctl --kubeconfig=<kubeconfig-path> delete --namespace default [--cascade=orphan] [--no-wait] [--timeout=5m] my-sub",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "namespace",
+				Aliases:     []string{"n"},
+				DefaultText: "default",
+				Value:       "default",
+				Usage:       "The namespace the subscription is in",
+			},
+			&cli.StringFlag{
+				Name:        "cascade",
+				DefaultText: cascadeBackground,
+				Value:       cascadeBackground,
+				Usage:       "Whether to delete the resources created for the subscription (background) or keep them (orphan).",
+			},
+			&cli.BoolFlag{
+				Name:  "no-wait",
+				Usage: "Don't wait for the subscription and its resources to be gone.",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Value: 5 * time.Minute,
+				Usage: "How long to wait for the subscription and its resources to be gone.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() < 1 {
+				return fmt.Errorf("subscription name must be provided")
+			}
+			cascade := c.String("cascade")
+			if cascade != cascadeBackground && cascade != cascadeOrphan {
+				return fmt.Errorf("unsupported --cascade %q, must be %s or %s", cascade, cascadeBackground, cascadeOrphan)
+			}
+			cl, err := buildK8sClient(c.String("kubeconfig"))
+			if err != nil {
+				return err
+			}
+			deleted, err := subscription.NewManager(cl).Delete(c.String("namespace"), c.Args().First(), subscription.DeleteOptions{
+				Orphan:   cascade == cascadeOrphan,
+				Wait:     !c.Bool("no-wait"),
+				Interval: 2 * time.Second,
+				Timeout:  c.Duration("timeout"),
+			})
+			for _, name := range deleted {
+				fmt.Printf("%s deleted\n", name)
+			}
+			return err
+		},
+	}
+}
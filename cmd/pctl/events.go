@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"github.com/weaveworks/pctl/pkg/formatter"
+	"github.com/weaveworks/pctl/pkg/subscription"
+)
+
+func eventsCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "events",
+		Usage:     "list the events of a profile Subscription and the resources it created",
+		UsageText: "pctl --kubeconfig=<kubeconfig-path> events --namespace default my-sub",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "namespace",
+				DefaultText: "default",
+				Value:       "default",
+				Usage:       "The namespace the subscription is in",
+			},
+		}, outputFlags()...),
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() < 1 {
+				return fmt.Errorf("subscription name must be provided")
+			}
+			cl, err := buildK8sClient(c.String("kubeconfig"))
+			if err != nil {
+				return err
+			}
+			events, err := subscription.NewManager(cl).Events(c.String("namespace"), c.Args().First())
+			if err != nil {
+				return err
+			}
+			if formatter.IsTable(outputFormat(c)) && len(events) == 0 {
+				fmt.Println("no events found")
+				return nil
+			}
+			return printOutput(c, eventsDataFunc(events))
+		},
+	}
+}
+
+// eventsDataFunc renders the events, the name output format prints the objects they are about.
+func eventsDataFunc(events []subscription.EventSummary) func() interface{} {
+	return func() interface{} {
+		p := formatter.Printable{
+			Table: formatter.TableContents{
+				Headers: []string{"Last Seen", "Type", "Reason", "Object", "Count", "Message"},
+			},
+			Object: events,
+		}
+		if events == nil {
+			p.Object = []subscription.EventSummary{}
+		}
+		seen := map[string]bool{}
+		for _, e := range events {
+			p.Table.Data = append(p.Table.Data, []string{
+				age(e.LastSeen),
+				e.Type,
+				e.Reason,
+				e.Kind + "/" + e.Name,
+				strconv.Itoa(int(e.Count)),
+				e.Message,
+			})
+			if name := strings.ToLower(e.Kind) + "/" + e.Name; !seen[name] {
+				seen[name] = true
+				p.Names = append(p.Names, name)
+			}
+		}
+		return p
+	}
+}
+
+// printEvents prints the events of a subscription as a section of the table output of another command.
+func printEvents(c *cli.Context, events []subscription.EventSummary) error {
+	fmt.Println("Events:")
+	if len(events) == 0 {
+		fmt.Println("  <none>")
+		return nil
+	}
+	return printOutput(c, eventsDataFunc(events))
+}
@@ -6,14 +6,15 @@ import (
 	"github.com/urfave/cli/v2"
 	"github.com/weaveworks/pctl/pkg/formatter"
 	"github.com/weaveworks/pctl/pkg/subscription"
+	"k8s.io/apimachinery/pkg/fields"
 )
 
 func getCmd() *cli.Command {
 	return &cli.Command{
 		Name:      "get",
 		Usage:     "get a profile Subscription",
-		UsageText: "pctl --kubeconfig=<kubeconfig-path> get --name my-sub --namespace default",
-		Flags: []cli.Flag{
+		UsageText: "pctl --kubeconfig=<kubeconfig-path> get --name my-sub --namespace default [--output table|json|yaml|name|jsonpath=<template>|go-template=<template>|custom-columns=<spec>] [--watch] [--wait-for=ready --timeout=5m]",
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:  "name",
 				Usage: "The name of the subscription.",
@@ -24,50 +25,78 @@ func getCmd() *cli.Command {
 				Value:       "default",
 				Usage:       "The namespace the subscription is in",
 			},
-		},
+		}, append(outputFlags(), watchFlags()...)...),
 		Action: func(c *cli.Context) error {
 			namespace := c.String("namespace")
 			name := c.String("name")
 			if name == "" {
 				return fmt.Errorf("subscrption name must be provided")
 			}
+			watch, err := watching(c)
+			if err != nil {
+				return err
+			}
+			if watch {
+				opts := subscription.ListOptions{
+					Namespace:     namespace,
+					FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
+				}
+				return watchSubscriptions(c, opts, func(profiles []subscription.SubscriptionSummary) error {
+					for _, profile := range profiles {
+						if err := printSubscription(c, profile); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+			}
 			cl, err := buildK8sClient(c.String("kubeconfig"))
 			if err != nil {
 				return err
 			}
-			profile, err := subscription.NewManager(cl).Get(namespace, name)
+			manager := subscription.NewManager(cl)
+			profile, err := manager.Get(namespace, name)
 			if err != nil {
 				return err
 			}
-			var f formatter.Formatter
-			f = formatter.NewTableFormatter()
-			getter := getDataFunc(profile)
-
-			if c.String("output") == "json" {
-				f = formatter.NewJSONFormatter()
-				getter = func() interface{} { return profile }
+			if err := printSubscription(c, profile); err != nil {
+				return err
 			}
-
-			out, err := f.Format(getter)
+			if !formatter.IsTable(outputFormat(c)) {
+				return nil
+			}
+			// events are extra information, so the subscription is still printed without them
+			events, err := manager.Events(namespace, name)
 			if err != nil {
-				return err
+				fmt.Println("Events:")
+				fmt.Printf("  warning: failed to list events: %s\n", err)
+				return nil
 			}
-
-			fmt.Println(out)
-			return nil
+			return printEvents(c, events)
 		},
 	}
 }
 
+func printSubscription(c *cli.Context, profile subscription.SubscriptionSummary) error {
+	return printOutput(c, getDataFunc(profile))
+}
+
 func getDataFunc(profile subscription.SubscriptionSummary) func() interface{} {
 	return func() interface{} {
-		return formatter.TableContents{
-			Data: [][]string{
-				{"Subscription", profile.Name},
-				{"Namespace", profile.Namespace},
-				{"Ready", profile.Ready},
-				{"Reason", profile.Message},
+		return formatter.Printable{
+			Table: formatter.TableContents{
+				Data: [][]string{
+					{"Subscription", profile.Name},
+					{"Namespace", profile.Namespace},
+					{"Ready", profile.Ready},
+					{"Reason", profile.Message},
+					{"URL", profile.ProfileURL},
+					{"Version", profile.Version},
+					{"Age", age(profile.Created)},
+				},
 			},
+			Names:  []string{subscriptionName(profile)},
+			Object: profile,
 		}
 	}
 }
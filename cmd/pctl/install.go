@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/urfave/cli/v2"
+	"github.com/urfave/cli/v2/altsrc"
 
 	"github.com/weaveworks/pctl/pkg/catalog"
 	"github.com/weaveworks/pctl/pkg/git"
@@ -14,65 +15,35 @@ import (
 )
 
 func installCmd() *cli.Command {
+	flags := installFlags()
 	return &cli.Command{
 		Name:      "install",
 		Usage:     "generate a profile subscription for a profile in a catalog",
-		UsageText: "pctl --catalog-url <URL> install --subscription-name pctl-profile --namespace default --branch main --config-secret configmap-name <CATALOG>/<PROFILE>[/<VERSION>]",
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:        "subscription-name",
-				DefaultText: "pctl-profile",
-				Value:       "pctl-profile",
-				Usage:       "The name of the subscription.",
-			},
-			&cli.StringFlag{
-				Name:        "namespace",
-				DefaultText: "default",
-				Value:       "default",
-				Usage:       "The namespace to use for generating resources.",
-			},
-			&cli.StringFlag{
-				Name:        "branch",
-				Value:       "main",
-				DefaultText: "main",
-				Usage:       "The branch to use on the repository in which the profile is.",
-			},
-			&cli.StringFlag{
-				Name:  "config-secret",
-				Value: "",
-				Usage: "The name of the ConfigMap which contains values for this profile.",
-			},
-			&cli.BoolFlag{
-				Name:  "create-pr",
-				Value: false,
-				Usage: "If given, install will create a PR for the modifications it outputs.",
-			},
-			&cli.StringFlag{
-				Name:        "remote",
-				Value:       "origin",
-				DefaultText: "origin",
-				Usage:       "The remote to push the branch to.",
-			},
-			&cli.StringFlag{
-				Name:        "base",
-				Value:       "main",
-				DefaultText: "main",
-				Usage:       "The base branch to open a PR against.",
-			},
-			&cli.StringFlag{
-				Name:  "repo",
-				Value: "",
-				Usage: "The repository to open a pr against. Format is: org/repo-name",
-			},
-		},
+		UsageText: "pctl --catalog-url <URL> install --subscription-name pctl-profile --namespace default --branch main --config-secret configmap-name <CATALOG>/<PROFILE>[/<VERSION>|/<CONSTRAINT>]",
+		Flags:     flags,
+		Before:    loadConfigFile(flags),
 		Action: func(c *cli.Context) error {
 			// Run installation main
-			if err := install(c); err != nil {
+			summary, err := install(c)
+			if err != nil {
 				return err
 			}
+			if summary.VersionConstraint != "" {
+				fmt.Printf("resolved version constraint %q to %s\n", summary.VersionConstraint, summary.Version)
+			}
+			// Apply to the cluster if desired
+			if c.Bool("apply") {
+				cl, err := buildK8sClient(c.String("kubeconfig"))
+				if err != nil {
+					return err
+				}
+				if err := catalog.Apply(cl, summary); err != nil {
+					return err
+				}
+			}
 			// Create a pull request if desired
 			if c.Bool("create-pr") {
-				if err := createPullRequest(c); err != nil {
+				if err := createPullRequest(c, summary); err != nil {
 					return err
 				}
 			}
@@ -81,12 +52,125 @@ func installCmd() *cli.Command {
 	}
 }
 
+func installFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "subscription-name",
+			DefaultText: "pctl-profile",
+			Value:       "pctl-profile",
+			Usage:       "The name of the subscription.",
+		},
+		&cli.StringFlag{
+			Name:        "namespace",
+			DefaultText: "default",
+			Value:       "default",
+			Usage:       "The namespace to use for generating resources.",
+		},
+		&cli.StringFlag{
+			Name:        "branch",
+			Value:       "main",
+			DefaultText: "main",
+			Usage:       "The branch to use on the repository in which the profile is.",
+		},
+		&cli.StringFlag{
+			Name:  "config-secret",
+			Value: "",
+			Usage: "The name of the ConfigMap which contains values for this profile.",
+		},
+		&cli.StringFlag{
+			Name:        "out",
+			Value:       ".",
+			DefaultText: "current directory",
+			Usage:       "The directory in which the profile directory is generated. Must be the root of a git repository if create-pr is set.",
+		},
+		&cli.BoolFlag{
+			Name:  "create-pr",
+			Value: false,
+			Usage: "If given, install will create a PR for the modifications it outputs.",
+		},
+		&cli.BoolFlag{
+			Name:  "apply",
+			Value: false,
+			Usage: "If given, install applies the generated resources directly to the cluster with server-side apply.",
+		},
+		&cli.StringFlag{
+			Name:        "remote",
+			Value:       "origin",
+			DefaultText: "origin",
+			Usage:       "The remote to push the branch to.",
+		},
+		&cli.StringFlag{
+			Name:        "base",
+			Value:       "main",
+			DefaultText: "main",
+			Usage:       "The base branch to open a PR against.",
+		},
+		&cli.StringFlag{
+			Name:  "repo",
+			Value: "",
+			Usage: "The repository to open a pr against. Format is: org/repo-name",
+		},
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "pr-title",
+			Usage: "Go template for the title of the created PR. Can also be set in the config file.",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "pr-body",
+			Usage: "Go template for the body of the created PR. Can also be set in the config file.",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "commit-message",
+			Usage: "Go template for the message of the commit pushed for the PR. Can also be set in the config file.",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "commit-author",
+			Usage: "The name of the author of the commit pushed for the PR. Defaults to the git configuration.",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "commit-email",
+			Usage: "The email of the author of the commit pushed for the PR. Defaults to the git configuration.",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "sign",
+			Usage: "Sign the commit pushed for the PR with the configured signing key: gpg or ssh.",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "git-provider",
+			Usage: "The git provider to open the PR with: github, gitlab or gitea. Detected from the url of the remote if not set.",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "git-server",
+			Usage: "The url of the git provider, for example for GitHub Enterprise or a self-hosted GitLab.",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:  "git-token-file",
+			Usage: "A file containing the token used to authenticate with the git provider. Defaults to $GIT_TOKEN.",
+		}),
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:  "pr-label",
+			Usage: "Label to add to the created PR. Can be repeated.",
+		}),
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:  "pr-reviewer",
+			Usage: "User to request a review from on the created PR. Can be repeated.",
+		}),
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:  "pr-assignee",
+			Usage: "User to assign to the created PR. Can be repeated.",
+		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:  "pr-draft",
+			Usage: "If given, the PR is opened as a draft.",
+		}),
+	}
+}
+
 // install runs the install part of the `install` command.
-func install(c *cli.Context) error {
+func install(c *cli.Context) (catalog.InstallSummary, error) {
 	profilePath, catalogClient, err := parseArgs(c)
 	if err != nil {
 		_ = cli.ShowCommandHelp(c, "install")
-		return err
+		return catalog.InstallSummary{}, err
 	}
 
 	branch := c.String("branch")
@@ -97,7 +181,7 @@ func install(c *cli.Context) error {
 	parts := strings.Split(profilePath, "/")
 	if len(parts) < 2 {
 		_ = cli.ShowCommandHelp(c, "install")
-		return errors.New("both catalog name and profile name must be provided")
+		return catalog.InstallSummary{}, errors.New("both catalog name and profile name must be provided")
 	}
 	catalogName, profileName := parts[0], parts[1]
 
@@ -110,6 +194,7 @@ func install(c *cli.Context) error {
 		Namespace:     namespace,
 		ProfileName:   profileName,
 		SubName:       subName,
+		Directory:     c.String("out"),
 	}
 	if len(parts) == 3 {
 		cfg.Version = parts[2]
@@ -118,31 +203,73 @@ func install(c *cli.Context) error {
 }
 
 // createPullRequest runs the pull request creation part of the `install` command.
-func createPullRequest(c *cli.Context) error {
+func createPullRequest(c *cli.Context, summary catalog.InstallSummary) error {
 	branch := c.String("branch")
-	filename := c.String("out")
 	repo := c.String("repo")
 	base := c.String("base")
-	remote := c.String("remote")
 	if repo == "" {
 		return errors.New("repo must be defined if create-pr is true")
 	}
+	messages, err := catalog.RenderMessages(catalog.MessageTemplates{
+		PullRequestTitle: c.String("pr-title"),
+		PullRequestBody:  c.String("pr-body"),
+		CommitMessage:    c.String("commit-message"),
+	}, summary)
+	if err != nil {
+		return err
+	}
 	fmt.Printf("Creating a PR to repo %s with base %s and branch %s\n", repo, base, branch)
+	gitCfg, err := gitConfig(c, summary, messages.CommitMessage)
+	if err != nil {
+		return err
+	}
 	r := &runner.CLIRunner{}
-	g := git.NewCLIGit(git.CLIGitConfig{
-		Filename: filename,
-		Location: filepath.Dir(filename),
-		Branch:   branch,
-		Remote:   remote,
-		Base:     base,
-	}, r)
+	g := git.NewCLIGit(gitCfg, r)
+	provider := git.ProviderConfig{
+		Provider:  c.String("git-provider"),
+		Server:    c.String("git-server"),
+		TokenFile: c.String("git-token-file"),
+	}
+	if provider.Provider == "" {
+		if provider.RemoteURL, err = g.RemoteURL(); err != nil {
+			return err
+		}
+	}
 	scmClient, err := git.NewClient(git.SCMConfig{
-		Branch: branch,
-		Base:   base,
-		Repo:   repo,
+		Branch:    branch,
+		Base:      base,
+		Repo:      repo,
+		Title:     messages.PullRequestTitle,
+		Body:      messages.PullRequestBody,
+		Labels:    c.StringSlice("pr-label"),
+		Reviewers: c.StringSlice("pr-reviewer"),
+		Assignees: c.StringSlice("pr-assignee"),
+		Draft:     c.Bool("pr-draft"),
+		Provider:  provider,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create scm client: %w", err)
 	}
 	return catalog.CreatePullRequest(scmClient, g)
 }
+
+// gitConfig returns the configuration of the repository at --out the pull request is created from. Git
+// resolves paths relative to the root of the work tree, so the profile directory is made relative to it.
+func gitConfig(c *cli.Context, summary catalog.InstallSummary, message string) (git.CLIGitConfig, error) {
+	location := c.String("out")
+	dir, err := filepath.Rel(location, summary.Directory)
+	if err != nil {
+		return git.CLIGitConfig{}, fmt.Errorf("failed to find profile directory in %s: %w", location, err)
+	}
+	return git.CLIGitConfig{
+		Paths:       []string{dir},
+		Location:    location,
+		Branch:      c.String("branch"),
+		Remote:      c.String("remote"),
+		Base:        c.String("base"),
+		Message:     message,
+		AuthorName:  c.String("commit-author"),
+		AuthorEmail: c.String("commit-email"),
+		Sign:        c.String("sign"),
+	}, nil
+}
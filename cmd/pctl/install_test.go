@@ -0,0 +1,74 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/pctl/pkg/catalog"
+	"github.com/weaveworks/pctl/pkg/git"
+	"github.com/weaveworks/pctl/pkg/runner"
+)
+
+var _ = Describe("install", func() {
+	var (
+		tmp string
+		wd  string
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = ioutil.TempDir("", "pctl-install")
+		Expect(err).NotTo(HaveOccurred())
+		wd, err = os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(tmp)).To(Succeed())
+		Expect(exec.Command("git", "init", "-q", "repo").Run()).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join("repo", "nginx"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join("repo", "nginx", "profile-subscription.yaml"), []byte("kind: ProfileSubscription\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join("repo", "README.md"), []byte("# repo\n"), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.Chdir(wd)).To(Succeed())
+		_ = os.RemoveAll(tmp)
+	})
+
+	// addProfile stages the profile generated into --out the way a pull request is created from it.
+	addProfile := func(args ...string) error {
+		cmd := installCmd()
+		cmd.Before = nil
+		cmd.Action = func(c *cli.Context) error {
+			cfg, err := gitConfig(c, catalog.InstallSummary{Directory: filepath.Join(c.String("out"), "nginx")}, "")
+			if err != nil {
+				return err
+			}
+			return git.NewCLIGit(cfg, &runner.CLIRunner{}).Add()
+		}
+		app := &cli.App{Flags: globalFlags(), Commands: []*cli.Command{cmd}}
+		return app.Run(append([]string{"pctl", "install"}, args...))
+	}
+
+	staged := func() string {
+		out, err := exec.Command("git", "-C", filepath.Join(tmp, "repo"), "diff", "--cached", "--name-only").Output()
+		Expect(err).NotTo(HaveOccurred())
+		return string(out)
+	}
+
+	It("adds the profile directory when --out is a relative path to the repository", func() {
+		Expect(addProfile("--out", "repo")).To(Succeed())
+		Expect(staged()).To(Equal("nginx/profile-subscription.yaml\n"))
+	})
+
+	It("adds the profile directory when --out is the current directory", func() {
+		Expect(os.Chdir("repo")).To(Succeed())
+		Expect(addProfile()).To(Succeed())
+		Expect(staged()).To(Equal("nginx/profile-subscription.yaml\n"))
+	})
+})
@@ -2,63 +2,148 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/urfave/cli/v2"
 	"github.com/weaveworks/pctl/pkg/formatter"
 	"github.com/weaveworks/pctl/pkg/subscription"
+	"k8s.io/apimachinery/pkg/util/duration"
 )
 
 func listCmd() *cli.Command {
 	return &cli.Command{
 		Name:      "list",
 		Usage:     "list profile subscriptions",
-		UsageText: "pctl --kubeconfig=<kubeconfig-path> list",
+		UsageText: "pctl --kubeconfig=<kubeconfig-path> list [--namespace default] [--output table|wide|json|yaml|name|jsonpath=<template>|go-template=<template>|custom-columns=<spec>] [--no-headers] [--sort-by <column>] [--selector team=a] [--not-ready] [--profile nginx] [--watch] [--wait-for=ready --timeout=5m]",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "namespace",
+				Aliases:     []string{"n"},
+				DefaultText: "all namespaces",
+				Usage:       "Only list subscriptions in this namespace.",
+			},
+			&cli.BoolFlag{
+				Name:    "all-namespaces",
+				Aliases: []string{"A"},
+				Usage:   "List subscriptions in all namespaces, even if --namespace is given. This is the default.",
+			},
+			&cli.StringFlag{
+				Name:    "selector",
+				Aliases: []string{"l"},
+				Usage:   "Only list subscriptions matching this label selector, such as team=a,env!=prod.",
+			},
+			&cli.StringFlag{
+				Name:  "field-selector",
+				Usage: "Only list subscriptions matching this field selector, such as metadata.name=my-sub.",
+			},
+			&cli.BoolFlag{
+				Name:  "not-ready",
+				Usage: "Only list subscriptions which aren't ready.",
+			},
+			&cli.StringFlag{
+				Name:  "profile",
+				Usage: "Only list subscriptions of the profile with this name.",
+			},
+		}, append(outputFlags(), watchFlags()...)...),
 		Action: func(c *cli.Context) error {
-			cl, err := buildK8sClient(c.String("kubeconfig"))
+			opts := listOptions(c)
+			watch, err := watching(c)
 			if err != nil {
 				return err
 			}
-			profiles, err := subscription.NewManager(cl).List()
+			if watch {
+				if opts.NotReady && c.String("wait-for") != "" {
+					return fmt.Errorf("--not-ready can't be used with --wait-for")
+				}
+				return watchSubscriptions(c, opts, func(profiles []subscription.SubscriptionSummary) error {
+					return printSubscriptions(c, profiles)
+				})
+			}
+			cl, err := buildK8sClient(c.String("kubeconfig"))
 			if err != nil {
 				return err
 			}
-			if len(profiles) == 0 {
-				fmt.Println("no profiles found")
-				return nil
-			}
-
-			var f formatter.Formatter
-			f = formatter.NewTableFormatter()
-			getter := listDataFunc(profiles)
-
-			if c.String("output") == "json" {
-				f = formatter.NewJSONFormatter()
-				getter = func() interface{} { return profiles }
-			}
-
-			out, err := f.Format(getter)
+			profiles, err := subscription.NewManager(cl).List(opts)
 			if err != nil {
 				return err
 			}
-
-			fmt.Println(out)
-			return nil
+			return printSubscriptions(c, profiles)
 		},
 	}
 }
 
+// listOptions returns the subscriptions selected by the flags. Subscriptions in all namespaces are listed unless
+// --namespace is given.
+func listOptions(c *cli.Context) subscription.ListOptions {
+	opts := subscription.ListOptions{
+		Namespace:     c.String("namespace"),
+		LabelSelector: c.String("selector"),
+		FieldSelector: c.String("field-selector"),
+		NotReady:      c.Bool("not-ready"),
+		Profile:       c.String("profile"),
+	}
+	if c.Bool("all-namespaces") {
+		opts.Namespace = ""
+	}
+	return opts
+}
+
+func printSubscriptions(c *cli.Context, profiles []subscription.SubscriptionSummary) error {
+	if formatter.IsTable(outputFormat(c)) && len(profiles) == 0 {
+		fmt.Println("no profiles found")
+		return nil
+	}
+	return printOutput(c, listDataFunc(profiles))
+}
+
 func listDataFunc(profiles []subscription.SubscriptionSummary) func() interface{} {
 	return func() interface{} {
-		tc := formatter.TableContents{
-			Headers: []string{"Namespace", "Name", "Ready"},
+		p := formatter.Printable{
+			Table: formatter.TableContents{
+				Headers: []string{"Namespace", "Name", "Ready", "URL", "Version", "Age"},
+			},
+			Wide: formatter.TableContents{
+				Headers: []string{"Namespace", "Name", "Ready", "Profile", "URL", "Version", "Message", "Age"},
+			},
+			Object: profiles,
+		}
+		if profiles == nil {
+			p.Object = []subscription.SubscriptionSummary{}
 		}
 		for _, profile := range profiles {
-			tc.Data = append(tc.Data, []string{
+			p.Table.Data = append(p.Table.Data, []string{
 				profile.Namespace,
 				profile.Name,
 				profile.Ready,
+				profile.ProfileURL,
+				profile.Version,
+				age(profile.Created),
 			})
+			p.Wide.Data = append(p.Wide.Data, []string{
+				profile.Namespace,
+				profile.Name,
+				profile.Ready,
+				profile.Profile,
+				profile.ProfileURL,
+				profile.Version,
+				profile.Message,
+				age(profile.Created),
+			})
+			p.Names = append(p.Names, subscriptionName(profile))
 		}
-		return tc
+		return p
+	}
+}
+
+// subscriptionName returns the name of a subscription for the name output format.
+func subscriptionName(profile subscription.SubscriptionSummary) string {
+	return "profilesubscription/" + profile.Name
+}
+
+// age returns how long ago something was created, like kubectl shows it.
+func age(created time.Time) string {
+	if created.IsZero() {
+		return "<unknown>"
 	}
+	return duration.HumanDuration(time.Since(created))
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"github.com/urfave/cli/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/pctl/pkg/formatter"
+	"github.com/weaveworks/pctl/pkg/subscription"
+)
+
+var _ = Describe("list", func() {
+	// runList returns the options the list command selects subscriptions with for args.
+	runList := func(args ...string) subscription.ListOptions {
+		var opts subscription.ListOptions
+		cmd := listCmd()
+		cmd.Action = func(c *cli.Context) error {
+			opts = listOptions(c)
+			return nil
+		}
+		app := &cli.App{Flags: globalFlags(), Commands: []*cli.Command{cmd}}
+		Expect(app.Run(append([]string{"pctl", "list"}, args...))).To(Succeed())
+		return opts
+	}
+
+	It("lists subscriptions in all namespaces by default", func() {
+		Expect(runList().Namespace).To(BeEmpty())
+	})
+
+	It("only lists subscriptions in the namespace given with -n", func() {
+		Expect(runList("-n", "team-a").Namespace).To(Equal("team-a"))
+		Expect(runList("-n", "team-a", "-A").Namespace).To(BeEmpty())
+	})
+
+	profiles := []subscription.SubscriptionSummary{
+		{Name: "sub1", Namespace: "default", Ready: "True", Profile: "nginx", ProfileURL: "https://github.com/org/repo", Version: "0.1.0"},
+		{Name: "sub2", Namespace: "default", Ready: "False", Profile: "nginx", ProfileURL: "https://github.com/org/repo", Version: "main"},
+	}
+
+	format := func(output string, options formatter.TableOptions) string {
+		f, err := formatter.New(output, options)
+		Expect(err).NotTo(HaveOccurred())
+		out, err := f.Format(listDataFunc(profiles))
+		Expect(err).NotTo(HaveOccurred())
+		return out
+	}
+
+	It("prints custom columns of the subscriptions by their json field names", func() {
+		out := format("custom-columns=NAME:.name,PROFILE:.profile,URL:.url,VERSION:.version", formatter.TableOptions{NoHeaders: true})
+		Expect(out).To(MatchRegexp(`sub1\s+nginx\s+https://github.com/org/repo\s+0.1.0`))
+		Expect(out).To(MatchRegexp(`sub2\s+nginx\s+https://github.com/org/repo\s+main`))
+		Expect(out).NotTo(ContainSubstring("<none>"))
+	})
+
+	It("prints a jsonpath template of the subscriptions", func() {
+		Expect(format(`jsonpath={range [*]}{.name}={.ready}{"\n"}{end}`, formatter.TableOptions{})).To(Equal("sub1=True\nsub2=False\n"))
+	})
+})
@@ -5,10 +5,16 @@ import (
 	"os"
 	"path/filepath"
 
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 	"github.com/urfave/cli/v2"
+	"github.com/urfave/cli/v2/altsrc"
 	"github.com/weaveworks/pctl/pkg/client"
 	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -28,7 +34,14 @@ func main() {
 			installCmd(),
 			listCmd(),
 			getCmd(),
+			statusCmd(),
+			eventsCmd(),
+			suspendCmd(),
+			resumeCmd(),
+			reconcileCmd(),
+			deleteCmd(),
 			prepareCmd(),
+			unprepareCmd(),
 		},
 	}
 
@@ -56,7 +69,7 @@ func globalFlags() []cli.Flag {
 		}
 	}
 
-	return []cli.Flag{
+	return append([]cli.Flag{
 		&cli.StringFlag{
 			Name:  "catalog-service-name",
 			Value: "profiles-catalog-service",
@@ -72,37 +85,77 @@ func globalFlags() []cli.Flag {
 			Value: "profiles-system",
 			Usage: "Catalog Kubernetes Service namespace",
 		},
+		&cli.StringFlag{
+			Name:  "config",
+			Usage: "Path to a yaml file containing values for command flags which support it (optional)",
+		},
 		kubeconfigFlag,
-	}
+	}, outputFlags()...)
 }
 
-func parseArgs(c *cli.Context) (string, *client.Client, error) {
-	options := client.ServiceOptions{
-		KubeconfigPath: c.String("kubeconfig"),
-		Namespace:      c.String("catalog-service-namespace"),
-		ServiceName:    c.String("catalog-service-name"),
-		ServicePort:    c.String("catalog-service-port"),
-	}
+// loadConfigFile returns a BeforeFunc which sets any of the given flags that weren't provided
+// on the command line from the yaml file given with --config.
+func loadConfigFile(flags []cli.Flag) cli.BeforeFunc {
+	return altsrc.InitInputSourceWithContext(flags, altsrc.NewYamlSourceFromFlagFunc("config"))
+}
 
+func parseArgs(c *cli.Context) (string, *client.Client, error) {
 	if c.Args().Len() < 1 {
 		return "", nil, fmt.Errorf("argument must be provided")
 	}
-	client, err := client.NewFromOptions(options)
+	client, err := newCatalogClient(c)
 	if err != nil {
 		return "", nil, err
 	}
 	return c.Args().First(), client, nil
 }
 
+// newCatalogClient returns a client of the catalog service given with the global flags.
+func newCatalogClient(c *cli.Context) (*client.Client, error) {
+	return client.NewFromOptions(client.ServiceOptions{
+		KubeconfigPath: c.String("kubeconfig"),
+		Namespace:      c.String("catalog-service-namespace"),
+		ServiceName:    c.String("catalog-service-name"),
+		ServicePort:    c.String("catalog-service-port"),
+	})
+}
+
 func buildK8sClient(kubeconfig string) (runtimeclient.Client, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	return buildK8sClientForContext(kubeconfig, "")
+}
+
+// buildK8sClientForContext creates a client for a context of the kubeconfig. If kubeContext is empty, the
+// current context is used.
+func buildK8sClientForContext(kubeconfig, kubeContext string) (runtimeclient.Client, error) {
+	config, err := buildRESTConfig(kubeconfig, kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	return newK8sClient(config)
+}
+
+// buildRESTConfig loads the configuration of a context of the kubeconfig. If kubeContext is empty, the
+// current context is used.
+func buildRESTConfig(kubeconfig, kubeContext string) (*rest.Config, error) {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext},
+	).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create config from kubeconfig path %q: %w", kubeconfig, err)
 	}
+	return config, nil
+}
+
+func newK8sClient(config *rest.Config) (runtimeclient.Client, error) {
 	cl, err := runtimeclient.New(config, runtimeclient.Options{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %w", err)
 	}
 	utilruntime.Must(profilesv1.AddToScheme(cl.Scheme()))
+	utilruntime.Must(apiextensionsv1.AddToScheme(cl.Scheme()))
+	utilruntime.Must(sourcev1.AddToScheme(cl.Scheme()))
+	utilruntime.Must(helmv2.AddToScheme(cl.Scheme()))
+	utilruntime.Must(kustomizev1.AddToScheme(cl.Scheme()))
 	return cl, nil
 }
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/weaveworks/pctl/pkg/formatter"
+)
+
+// outputFlags are the -o flag of pctl and the flags changing how tables are printed. Commands which print
+// profiles or subscriptions define them as well, so they can be given either before or after the command.
+func outputFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "output",
+			Aliases:     []string{"o"},
+			DefaultText: "table",
+			Value:       "table",
+			Usage:       "Output format. table|wide|json|yaml|name|jsonpath=<template>|go-template=<template>|custom-columns=<HEADER>:<jsonpath>,...",
+		},
+		&cli.BoolFlag{
+			Name:  "no-headers",
+			Usage: "Don't print the header row of tables.",
+		},
+		&cli.StringFlag{
+			Name:  "sort-by",
+			Usage: "The column to sort the rows of tables by, such as version or age.",
+		},
+	}
+}
+
+// outputFormat returns the -o flag given to the command, or else to pctl.
+func outputFormat(c *cli.Context) string {
+	if ctx := flagContext(c, "output"); ctx != nil {
+		return ctx.String("output")
+	}
+	return "table"
+}
+
+// tableOptions returns the table flags given to the command or to pctl.
+func tableOptions(c *cli.Context) formatter.TableOptions {
+	var options formatter.TableOptions
+	if ctx := flagContext(c, "no-headers"); ctx != nil {
+		options.NoHeaders = ctx.Bool("no-headers")
+	}
+	if ctx := flagContext(c, "sort-by"); ctx != nil {
+		options.SortBy = ctx.String("sort-by")
+	}
+	return options
+}
+
+// flagContext returns the context of the command or of pctl in which a flag was set, or nil if it wasn't.
+func flagContext(c *cli.Context, name string) *cli.Context {
+	for _, ctx := range c.Lineage() {
+		if ctx.IsSet(name) {
+			return ctx
+		}
+	}
+	return nil
+}
+
+// printOutput prints the formatter.Printable returned by getter in the output format.
+func printOutput(c *cli.Context, getter func() interface{}) error {
+	f, err := formatter.New(outputFormat(c), tableOptions(c))
+	if err != nil {
+		return err
+	}
+
+	out, err := f.Format(getter)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(out)
+	return nil
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 
 	"github.com/urfave/cli/v2"
+	"k8s.io/client-go/discovery"
 
 	"github.com/weaveworks/pctl/pkg/cluster"
 )
@@ -17,71 +19,220 @@ func prepareCmd() *cli.Command {
 		Name:      "prepare",
 		Usage:     "prepare an environment with everything profiles need to work",
 		UsageText: "pctl prepare",
-		Flags: []cli.Flag{
+		Flags: append(prepareFlags(),
 			&cli.BoolFlag{
-				Name:  "dry-run",
-				Usage: "If defined, nothing will be applied.",
+				Name:  "uninstall",
+				Usage: "Remove everything prepare installed instead. Same as pctl unprepare.",
 				Value: false,
 			},
 			&cli.BoolFlag{
-				Name:  "keep",
-				Usage: "Keep the downloaded manifest files.",
+				Name:  "force",
+				Usage: "Allow downgrading an installed profiles controller to an older version.",
 				Value: false,
 			},
 			&cli.BoolFlag{
-				Name:  "ignore-preflight-errors",
-				Usage: "Instead of stopping the process, output warnings when they occur during preflight check.",
+				Name:  "install-flux",
+				Usage: "Install flux into the flux-system namespace first if it isn't installed yet.",
 				Value: false,
 			},
 			&cli.StringFlag{
-				Name:  "version",
-				Usage: "Define the tagged version to use which can be found under releases in the profiles repository. Exp: [v]0.0.1",
+				Name:        "flux-version",
+				Usage:       "The flux release to install with --install-flux.",
+				Value:       cluster.DefaultFluxVersion,
+				DefaultText: cluster.DefaultFluxVersion,
 			},
 			&cli.StringFlag{
-				Name:        "baseurl",
-				Usage:       "Define the url to go and fetch releases from.",
-				Value:       releaseUrl,
-				DefaultText: releaseUrl,
+				Name:        "flux-baseurl",
+				Usage:       "Define the url to go and fetch flux releases from.",
+				Value:       cluster.DefaultFluxBaseURL,
+				DefaultText: cluster.DefaultFluxBaseURL,
 			},
-			&cli.StringFlag{
-				Name:        "flux-namespace",
-				Usage:       "Define the namespace in which flux is installed.",
-				Value:       fluxNamespace,
-				DefaultText: fluxNamespace,
-			},
-			&cli.StringFlag{
-				Name:        "out",
-				Usage:       "Specify the output location of the downloaded prepare file.",
-				Value:       "",
-				DefaultText: "os.Temp",
-			},
-			&cli.StringFlag{
-				Name:  "context",
-				Usage: "The Kubernetes context to use to apply the manifest files .",
+			&cli.BoolFlag{
+				Name:  "download-only",
+				Usage: "Only download and verify the manifest files into the --out directory, which can then be used with --bundle.",
+				Value: false,
 			},
-		},
+		),
 		Action: func(c *cli.Context) error {
-			kubeConfig := c.String("kubeconfig")
-			k8sClient, err := buildK8sClient(kubeConfig)
-			if err != nil {
-				return fmt.Errorf("failed to build kubernetes client: %w", err)
+			if c.Bool("download-only") && c.String("out") == "" {
+				return fmt.Errorf("out must be defined if download-only is set")
 			}
-			p, err := cluster.NewPreparer(cluster.PrepConfig{
-				BaseURL:               c.String("baseurl"),
-				Version:               c.String("version"),
-				KubeConfig:            c.String("kubeconfig"),
-				KubeContext:           c.String("context"),
-				FluxNamespace:         c.String("flux-namespace"),
-				Location:              c.String("out"),
-				DryRun:                c.Bool("dry-run"),
-				Keep:                  c.Bool("keep"),
-				IgnorePreflightErrors: c.Bool("ignore-preflight-errors"),
-				K8sClient:             k8sClient,
-			})
+			p, err := newPreparer(c)
 			if err != nil {
 				return err
 			}
+			switch {
+			case c.Bool("download-only"):
+				return p.Download()
+			case c.Bool("uninstall"):
+				return p.Unprepare()
+			}
 			return p.Prepare()
 		},
 	}
 }
+
+func unprepareCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "unprepare",
+		Usage:     "remove everything prepare installed from an environment",
+		UsageText: "pctl unprepare",
+		Flags:     prepareFlags(),
+		Action: func(c *cli.Context) error {
+			p, err := newPreparer(c)
+			if err != nil {
+				return err
+			}
+			return p.Unprepare()
+		},
+	}
+}
+
+func prepareFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "If defined, nothing will be applied.",
+			Value: false,
+		},
+		&cli.BoolFlag{
+			Name:  "keep",
+			Usage: "Keep the downloaded manifest files.",
+			Value: false,
+		},
+		&cli.BoolFlag{
+			Name:  "ignore-preflight-errors",
+			Usage: "Instead of stopping the process, output warnings when they occur during preflight check.",
+			Value: false,
+		},
+		&cli.StringFlag{
+			Name:  "version",
+			Usage: "Define the tagged version to use which can be found under releases in the profiles repository. Exp: [v]0.0.1",
+		},
+		&cli.StringFlag{
+			Name:        "baseurl",
+			Usage:       "Define the url to go and fetch releases from.",
+			Value:       releaseUrl,
+			DefaultText: releaseUrl,
+		},
+		&cli.StringFlag{
+			Name:        "flux-namespace",
+			Usage:       "Define the namespace in which flux is installed.",
+			Value:       fluxNamespace,
+			DefaultText: fluxNamespace,
+		},
+		&cli.StringFlag{
+			Name:        "out",
+			Usage:       "Specify the output location of the downloaded prepare file.",
+			Value:       "",
+			DefaultText: "os.Temp",
+		},
+		&cli.StringFlag{
+			Name:  "context",
+			Usage: "The Kubernetes context to use to apply the manifest files .",
+		},
+		&cli.StringFlag{
+			Name:  "from-file",
+			Usage: "Apply a local manifest file instead of downloading one. The file is not verified.",
+		},
+		&cli.StringFlag{
+			Name:  "bundle",
+			Usage: "Apply the manifest files in a directory created with --download-only instead of downloading them.",
+		},
+		&cli.BoolFlag{
+			Name:  "insecure-skip-verify",
+			Usage: "Don't verify the checksum and signature of the downloaded manifest files.",
+			Value: false,
+		},
+		&cli.StringFlag{
+			Name:  "signature-type",
+			Usage: "Verify the signature of the release checksums with the public key given with --public-key: cosign or minisign.",
+		},
+		&cli.DurationFlag{
+			Name:  "wait-timeout",
+			Usage: "How long to wait for resources to be ready or removed.",
+			Value: cluster.DefaultWaitTimeout,
+		},
+		&cli.BoolFlag{
+			Name:  "no-wait",
+			Usage: "Don't wait for resources to be ready or removed.",
+			Value: false,
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "Format of the preflight check report: table or json.",
+			Value:   "table",
+		},
+		&cli.StringFlag{
+			Name:  "public-key",
+			Usage: "Path to the public key used to verify the signature of the release checksums.",
+		},
+	}
+}
+
+// newPreparer creates a preparer from the flags shared by prepare and unprepare.
+func newPreparer(c *cli.Context) (*cluster.Preparer, error) {
+	cfg, err := prepConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	if !c.Bool("download-only") {
+		config, err := buildRESTConfig(c.String("kubeconfig"), c.String("context"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build kubernetes client: %w", err)
+		}
+		if cfg.K8sClient, err = newK8sClient(config); err != nil {
+			return nil, fmt.Errorf("failed to build kubernetes client: %w", err)
+		}
+		if cfg.Discovery, err = discovery.NewDiscoveryClientForConfig(config); err != nil {
+			return nil, fmt.Errorf("failed to build discovery client: %w", err)
+		}
+	}
+	return cluster.NewPreparer(cfg)
+}
+
+// prepConfig returns the configuration of prepare and unprepare given with the flags, without the clients.
+func prepConfig(c *cli.Context) (cluster.PrepConfig, error) {
+	if c.String("from-file") != "" && c.String("bundle") != "" {
+		return cluster.PrepConfig{}, fmt.Errorf("only one of from-file and bundle can be defined")
+	}
+	output := outputFormat(c)
+	if output != "table" && output != cluster.OutputJSON {
+		return cluster.PrepConfig{}, fmt.Errorf("unsupported output %q, must be table or json", output)
+	}
+	verify := cluster.VerifyConfig{
+		InsecureSkipVerify: c.Bool("insecure-skip-verify"),
+		SignatureType:      c.String("signature-type"),
+	}
+	if verify.SignatureType != "" {
+		if c.String("public-key") == "" {
+			return cluster.PrepConfig{}, fmt.Errorf("public-key must be defined if signature-type is set")
+		}
+		var err error
+		if verify.PublicKey, err = ioutil.ReadFile(c.String("public-key")); err != nil {
+			return cluster.PrepConfig{}, fmt.Errorf("failed to read public key: %w", err)
+		}
+	}
+	return cluster.PrepConfig{
+		BaseURL:                 c.String("baseurl"),
+		Version:                 c.String("version"),
+		FluxNamespace:           c.String("flux-namespace"),
+		Location:                c.String("out"),
+		DryRun:                  c.Bool("dry-run"),
+		Keep:                    c.Bool("keep"),
+		IgnorePreflightErrors:   c.Bool("ignore-preflight-errors"),
+		InstallFlux:             c.Bool("install-flux"),
+		FluxVersion:             c.String("flux-version"),
+		FluxBaseURL:             c.String("flux-baseurl"),
+		WaitTimeout:             c.Duration("wait-timeout"),
+		NoWait:                  c.Bool("no-wait"),
+		Force:                   c.Bool("force"),
+		CatalogServiceName:      c.String("catalog-service-name"),
+		CatalogServiceNamespace: c.String("catalog-service-namespace"),
+		Output:                  output,
+		Verify:                  verify,
+		FromFile:                c.String("from-file"),
+		Bundle:                  c.String("bundle"),
+	}, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/pctl/pkg/cluster"
+)
+
+var _ = Describe("prepare", func() {
+	var (
+		tmp      string
+		server   *httptest.Server
+		requests []string
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = ioutil.TempDir("", "pctl-prepare")
+		Expect(err).NotTo(HaveOccurred())
+		requests = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.URL.Path)
+			_, _ = w.Write([]byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: flux-system\n"))
+		}))
+		Expect(ioutil.WriteFile(filepath.Join(tmp, "prepare.yaml"), []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: profiles-system\n"), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		_ = os.RemoveAll(tmp)
+	})
+
+	// runPrepare runs the prepare command with args against a fake cluster without any checks.
+	runPrepare := func(args ...string) (*cluster.Preparer, error) {
+		var p *cluster.Preparer
+		cmd := prepareCmd()
+		cmd.Action = func(c *cli.Context) error {
+			cfg, err := prepConfig(c)
+			if err != nil {
+				return err
+			}
+			cfg.K8sClient = fake.NewClientBuilder().Build()
+			if p, err = cluster.NewPreparer(cfg); err != nil {
+				return err
+			}
+			p.Checks = []cluster.Check{}
+			return p.Prepare()
+		}
+		app := &cli.App{Flags: globalFlags(), Commands: []*cli.Command{cmd}}
+		base := []string{"pctl", "prepare", "--dry-run", "--keep", "--out", tmp, "--from-file", filepath.Join(tmp, "prepare.yaml")}
+		return p, app.Run(append(base, args...))
+	}
+
+	It("installs flux with the version and url given with the flags", func() {
+		p, err := runPrepare("--install-flux", "--flux-version", "v0.15.0", "--flux-baseurl", server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.InstallFlux).To(BeTrue())
+		Expect(requests).To(Equal([]string{"/download/v0.15.0/install.yaml"}))
+	})
+
+	It("doesn't install flux by default", func() {
+		p, err := runPrepare("--flux-baseurl", server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.InstallFlux).To(BeFalse())
+		Expect(p.FluxVersion).To(Equal(cluster.DefaultFluxVersion))
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("waits as long as given with the flags", func() {
+		p, err := runPrepare("--wait-timeout", "2m", "--no-wait")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.WaitTimeout).To(Equal(2 * time.Minute))
+		Expect(p.Applier.NoWait).To(BeTrue())
+	})
+
+	It("waits for the default timeout", func() {
+		p, err := runPrepare()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.WaitTimeout).To(Equal(cluster.DefaultWaitTimeout))
+		Expect(p.Applier.NoWait).To(BeFalse())
+	})
+})
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"github.com/weaveworks/pctl/pkg/subscription"
+)
+
+func reconcileCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "reconcile",
+		Usage:     "reconcile a profile Subscription and the resources it created right away",
+		UsageText: "pctl --kubeconfig=<kubeconfig-path> reconcile --namespace default [--no-wait] [--timeout=5m] my-sub",
+		Flags: append([]cli.Flag{
+			subscriptionNamespaceFlag(),
+			&cli.BoolFlag{
+				Name:  "no-wait",
+				Usage: "Don't wait for the resources to be reconciled.",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Value: 5 * time.Minute,
+				Usage: "How long to wait for the resources to be reconciled.",
+			},
+		}, outputFlags()...),
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() < 1 {
+				return fmt.Errorf("subscription name must be provided")
+			}
+			namespace, name := c.String("namespace"), c.Args().First()
+			cl, err := buildK8sClient(c.String("kubeconfig"))
+			if err != nil {
+				return err
+			}
+			manager := subscription.NewManager(cl)
+			requestedAt, err := manager.Reconcile(namespace, name)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Reconciliation of %s/%s requested.\n", namespace, name)
+			if c.Bool("no-wait") {
+				return nil
+			}
+			fmt.Println("Waiting for the resources to be reconciled...")
+			// the status is printed as it was when the resources were reconciled, even if some of them failed
+			status, waitErr := manager.WaitForReconcile(namespace, name, requestedAt, 2*time.Second, c.Duration("timeout"))
+			if status.Name != "" {
+				if err := printOutput(c, statusDataFunc(status)); err != nil {
+					return err
+				}
+			}
+			return waitErr
+		},
+	}
+}
@@ -15,50 +15,86 @@ func searchCmd() *cli.Command {
 	return &cli.Command{
 		Name:      "search",
 		Usage:     "search for a profile",
-		UsageText: "pctl [--kubeconfig=<kubeconfig-path>] search [--output table|json] <QUERY>",
-		Flags: []cli.Flag{
+		UsageText: "pctl [--kubeconfig=<kubeconfig-path>] search [--match substring|regex|fuzzy] [--catalog <CATALOG>] [--maintainer <NAME>] [--tag <TAG>] [--prerelease] [--page 1 --page-size 20] [--output table|wide|json|yaml|name|jsonpath=<template>|go-template=<template>|custom-columns=<spec>] [--no-headers] [--sort-by <column>] [<QUERY>]",
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
-				Name:        "output",
-				Aliases:     []string{"o"},
-				DefaultText: "table",
-				Value:       "table",
-				Usage:       "Output format. json|table",
+				Name:        "match",
+				DefaultText: catalog.MatchSubstring,
+				Value:       catalog.MatchSubstring,
+				Usage:       "How the query is matched against profile names: substring, regex or fuzzy.",
 			},
-		},
+			&cli.StringFlag{
+				Name:  "catalog",
+				Usage: "Only show profiles of the catalog with this name.",
+			},
+			&cli.StringFlag{
+				Name:  "maintainer",
+				Usage: "Only show profiles whose maintainer contains this, ignoring case.",
+			},
+			&cli.StringFlag{
+				Name:  "tag",
+				Usage: "Only show the profile release with this git tag, such as v0.1.0 or weaveworks-nginx/v0.1.0.",
+			},
+			&cli.BoolFlag{
+				Name:  "prerelease",
+				Usage: "Show prerelease versions, such as 0.2.0-rc.1, as well.",
+			},
+			&cli.IntFlag{
+				Name:  "page",
+				Value: 1,
+				Usage: "The page of profiles to show when no query is given.",
+			},
+			&cli.IntFlag{
+				Name:  "page-size",
+				Value: 20,
+				Usage: "The number of profiles on a page when no query is given. 0 shows all of them.",
+			},
+		}, outputFlags()...),
 		Action: func(c *cli.Context) error {
-			searchName, catalogClient, err := parseArgs(c)
+			catalogClient, err := newCatalogClient(c)
 			if err != nil {
-				_ = cli.ShowCommandHelp(c, "search")
 				return err
 			}
-
-			profiles, err := catalog.Search(catalogClient, searchName)
+			opts := catalog.SearchOptions{
+				Term:       c.Args().First(),
+				Match:      c.String("match"),
+				Catalog:    c.String("catalog"),
+				Maintainer: c.String("maintainer"),
+				Tag:        c.String("tag"),
+				Prerelease: c.Bool("prerelease"),
+			}
+			profiles, err := catalog.Search(catalogClient, opts)
 			if err != nil {
 				return err
 			}
-			outFormat := c.String("output")
-			if outFormat == "table" {
-				if len(profiles) == 0 {
-					fmt.Printf("No profiles found matching: '%s'\n", searchName)
-					return nil
+			table := formatter.IsTable(outputFormat(c))
+			if table && len(profiles) == 0 {
+				if opts.Term == "" {
+					fmt.Println("No profiles found")
+				} else {
+					fmt.Printf("No profiles found matching: '%s'\n", opts.Term)
 				}
+				return nil
 			}
-
-			var f formatter.Formatter
-			f = formatter.NewTableFormatter()
-			getter := searchDataFunc(profiles)
-
-			if outFormat == "json" {
-				f = formatter.NewJSONFormatter()
-				getter = func() interface{} { return profiles }
+			if opts.Term != "" {
+				return printOutput(c, searchDataFunc(profiles))
 			}
 
-			out, err := f.Format(getter)
-			if err != nil {
+			page, size := c.Int("page"), c.Int("page-size")
+			if page < 1 {
+				return fmt.Errorf("page must be at least 1")
+			}
+			shown := catalog.Page(profiles, page, size)
+			if table && len(shown) == 0 {
+				fmt.Printf("No profiles on page %d, there are %d profiles\n", page, len(profiles))
+				return nil
+			}
+			if err := printOutput(c, searchDataFunc(shown)); err != nil {
 				return err
 			}
-
-			fmt.Println(out)
+			if first := (page - 1) * size; table && first+len(shown) < len(profiles) {
+				fmt.Printf("Showing %d-%d of %d profiles, use --page %d for more.\n", first+1, first+len(shown), len(profiles), page+1)
+			}
 			return nil
 		},
 	}
@@ -66,16 +102,21 @@ func searchCmd() *cli.Command {
 
 func searchDataFunc(profiles []profilesv1.ProfileDescription) func() interface{} {
 	return func() interface{} {
-		tc := formatter.TableContents{
-			Headers: []string{"Catalog/Profile", "Version", "Description"},
+		p := formatter.Printable{
+			Table: formatter.TableContents{
+				Headers: []string{"Catalog/Profile", "Version", "Description"},
+			},
+			Wide: formatter.TableContents{
+				Headers: []string{"Catalog/Profile", "Version", "Description", "URL", "Maintainer"},
+			},
+			Object: profiles,
 		}
 		for _, profile := range profiles {
-			tc.Data = append(tc.Data, []string{
-				fmt.Sprintf("%s/%s", profile.CatalogSource, profile.Name),
-				profile.Version,
-				profile.Description,
-			})
+			name := fmt.Sprintf("%s/%s", profile.CatalogSource, profile.Name)
+			p.Table.Data = append(p.Table.Data, []string{name, profile.Version, profile.Description})
+			p.Wide.Data = append(p.Wide.Data, []string{name, profile.Version, profile.Description, profile.URL, profile.Maintainer})
+			p.Names = append(p.Names, name)
 		}
-		return tc
+		return p
 	}
 }
@@ -17,16 +17,17 @@ func showCmd() *cli.Command {
 	return &cli.Command{
 		Name:      "show",
 		Usage:     "display information about a profile",
-		UsageText: "pctl [--kubeconfig=<kubeconfig-path>] show <CATALOG>/<PROFILE>[/<VERSION>]",
-		Flags: []cli.Flag{
+		UsageText: "pctl [--kubeconfig=<kubeconfig-path>] show [--versions [--subscription-dir <DIRECTORY>]] [--output table|json|yaml|name|jsonpath=<template>|go-template=<template>|custom-columns=<spec>] <CATALOG>/<PROFILE>[/<VERSION>]",
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "versions",
+				Usage: "List all versions of the profile in the catalog, newest first.",
+			},
 			&cli.StringFlag{
-				Name:        "output",
-				Aliases:     []string{"o"},
-				DefaultText: "table",
-				Value:       "table",
-				Usage:       "Output format. json|table",
+				Name:  "subscription-dir",
+				Usage: "The directory of a subscription generated by install, to mark its version when listing versions.",
 			},
-		},
+		}, outputFlags()...),
 		Action: func(c *cli.Context) error {
 			profilePath, catalogClient, err := parseArgs(c)
 			if err != nil {
@@ -44,43 +45,69 @@ func showCmd() *cli.Command {
 			if len(parts) == 3 {
 				catalogVersion = parts[2]
 			}
-			profile, err := catalog.Show(catalogClient, catalogName, profileName, catalogVersion)
-			if err != nil {
-				return err
-			}
-
-			var f formatter.Formatter
-			f = formatter.NewTableFormatter()
-			getter := showDataFunc(profile)
-
-			if c.String("output") == "json" {
-				f = formatter.NewJSONFormatter()
-				getter = func() interface{} { return profile }
+			if c.Bool("versions") {
+				if catalogVersion != "" {
+					return errors.New("a version can't be given with --versions")
+				}
+				versions, err := catalog.Versions(catalogClient, catalogName, profileName)
+				if err != nil {
+					return err
+				}
+				if dir := c.String("subscription-dir"); dir != "" && catalog.MarkInstalled(versions, dir) == "" {
+					return fmt.Errorf("no subscription with a version found in %s", dir)
+				}
+				return printOutput(c, versionsDataFunc(catalogName, profileName, versions))
 			}
-
-			out, err := f.Format(getter)
+			profile, err := catalog.Show(catalogClient, catalogName, profileName, catalogVersion)
 			if err != nil {
 				return err
 			}
 
-			fmt.Println(out)
-			return nil
+			return printOutput(c, showDataFunc(profile))
 		},
 	}
 }
 
 func showDataFunc(profile profilesv1.ProfileDescription) func() interface{} {
 	return func() interface{} {
-		return formatter.TableContents{
-			Data: [][]string{
-				{"Catalog", profile.CatalogSource},
-				{"Name", profile.Name},
-				{"Version", profile.Version},
-				{"Description", profile.Description},
-				{"URL", profile.URL},
-				{"Maintainer", profile.Maintainer},
-				{"Prerequisites", strings.Join(profile.Prerequisites, ", ")},
+		return formatter.Printable{
+			Table: formatter.TableContents{
+				Data: [][]string{
+					{"Catalog", profile.CatalogSource},
+					{"Name", profile.Name},
+					{"Version", profile.Version},
+					{"Description", profile.Description},
+					{"URL", profile.URL},
+					{"Maintainer", profile.Maintainer},
+					{"Prerequisites", strings.Join(profile.Prerequisites, ", ")},
+				},
 			},
+			Names:  []string{fmt.Sprintf("%s/%s", profile.CatalogSource, profile.Name)},
+			Object: profile,
 		}
 	}
 }
+
+func versionsDataFunc(catalogName, profileName string, versions []catalog.ProfileVersion) func() interface{} {
+	return func() interface{} {
+		p := formatter.Printable{
+			Table: formatter.TableContents{
+				Headers: []string{"Version", "Prerelease", "Installed"},
+			},
+			Object: versions,
+		}
+		for _, v := range versions {
+			p.Table.Data = append(p.Table.Data, []string{v.Version, marker(v.Prerelease), marker(v.Installed)})
+			p.Names = append(p.Names, fmt.Sprintf("%s/%s/%s", catalogName, profileName, v.Version))
+		}
+		return p
+	}
+}
+
+// marker returns the text of a column marking something as true, which is empty otherwise.
+func marker(b bool) string {
+	if b {
+		return "yes"
+	}
+	return ""
+}
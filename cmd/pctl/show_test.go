@@ -0,0 +1,31 @@
+package main
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/pctl/pkg/catalog"
+	"github.com/weaveworks/pctl/pkg/formatter"
+)
+
+var _ = Describe("show --versions", func() {
+	versions := []catalog.ProfileVersion{
+		{Version: "0.2.0-rc.1", Prerelease: true},
+		{Version: "0.1.0", Installed: true},
+	}
+
+	format := func(output string) string {
+		f, err := formatter.New(output, formatter.TableOptions{NoHeaders: true})
+		Expect(err).NotTo(HaveOccurred())
+		out, err := f.Format(versionsDataFunc("nginx-catalog", "nginx", versions))
+		Expect(err).NotTo(HaveOccurred())
+		return out
+	}
+
+	It("prints custom columns of the versions by their json field names", func() {
+		out := format("custom-columns=VERSION:.version,PRERELEASE:.prerelease,INSTALLED:.installed")
+		Expect(out).To(MatchRegexp(`0\.2\.0-rc\.1\s+true\s+false`))
+		Expect(out).To(MatchRegexp(`0\.1\.0\s+false\s+true`))
+		Expect(out).NotTo(ContainSubstring("<none>"))
+	})
+})
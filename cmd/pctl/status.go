@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"github.com/weaveworks/pctl/pkg/formatter"
+	"github.com/weaveworks/pctl/pkg/subscription"
+)
+
+func statusCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "status",
+		Usage:     "show the status of a profile Subscription and the resources it created",
+		UsageText: "pctl --kubeconfig=<kubeconfig-path> status --namespace default my-sub",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:        "namespace",
+				DefaultText: "default",
+				Value:       "default",
+				Usage:       "The namespace the subscription is in",
+			},
+		}, outputFlags()...),
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() < 1 {
+				return fmt.Errorf("subscription name must be provided")
+			}
+			cl, err := buildK8sClient(c.String("kubeconfig"))
+			if err != nil {
+				return err
+			}
+			status, err := subscription.NewManager(cl).Status(c.String("namespace"), c.Args().First())
+			if err != nil {
+				return err
+			}
+			return printOutput(c, statusDataFunc(status))
+		},
+	}
+}
+
+// statusDataFunc renders the subscription as the root of a tree with its resources as the leaves.
+func statusDataFunc(status subscription.SubscriptionStatus) func() interface{} {
+	return func() interface{} {
+		p := formatter.Printable{
+			Table: formatter.TableContents{
+				Headers: []string{"Name", "Ready", "Revision", "Message"},
+				Data: [][]string{
+					{"ProfileSubscription/" + status.Name, status.Ready, "-", status.Message},
+				},
+			},
+			Names:  []string{subscriptionName(status.SubscriptionSummary)},
+			Object: status,
+		}
+		for i, r := range status.Resources {
+			branch := "├── "
+			if i == len(status.Resources)-1 {
+				branch = "└── "
+			}
+			p.Table.Data = append(p.Table.Data, []string{
+				branch + r.Kind + "/" + r.Name,
+				r.Ready,
+				r.Revision,
+				r.Message,
+			})
+			p.Names = append(p.Names, strings.ToLower(r.Kind)+"/"+r.Name)
+		}
+		return p
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/pctl/pkg/formatter"
+	"github.com/weaveworks/pctl/pkg/subscription"
+)
+
+var _ = Describe("status", func() {
+	status := subscription.SubscriptionStatus{
+		SubscriptionSummary: subscription.SubscriptionSummary{Name: "sub1", Namespace: "default", Ready: "True", Message: "-"},
+		Resources: []subscription.ResourceStatus{
+			{Kind: "GitRepository", Name: "sub1-repo-main", Ready: "True", Revision: "main/abc", Message: "fetched"},
+			{Kind: "HelmRelease", Name: "sub1-nginx-server", Ready: "False", Revision: "-", Message: "failed"},
+		},
+	}
+
+	format := func(output string) string {
+		f, err := formatter.New(output, formatter.TableOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		out, err := f.Format(statusDataFunc(status))
+		Expect(err).NotTo(HaveOccurred())
+		return out
+	}
+
+	It("prints the subscription and its resources as a tree", func() {
+		out := format("table")
+		Expect(out).To(ContainSubstring("ProfileSubscription/sub1"))
+		Expect(out).To(ContainSubstring("├── GitRepository/sub1-repo-main"))
+		Expect(out).To(ContainSubstring("└── HelmRelease/sub1-nginx-server"))
+	})
+
+	It("prints the names of the subscription and its resources", func() {
+		Expect(format("name")).To(Equal("profilesubscription/sub1\ngitrepository/sub1-repo-main\nhelmrelease/sub1-nginx-server"))
+	})
+})
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+	"github.com/weaveworks/pctl/pkg/subscription"
+)
+
+func suspendCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "suspend",
+		Usage:     "suspend the HelmReleases and Kustomizations of a profile Subscription",
+		UsageText: "pctl --kubeconfig=<kubeconfig-path> suspend --namespace default my-sub",
+		Flags:     []cli.Flag{subscriptionNamespaceFlag()},
+		Action: func(c *cli.Context) error {
+			return setSuspend(c, "suspended", (*subscription.Manager).Suspend)
+		},
+	}
+}
+
+func resumeCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "resume",
+		Usage:     "resume the HelmReleases and Kustomizations of a profile Subscription",
+		UsageText: "pctl --kubeconfig=<kubeconfig-path> resume --namespace default my-sub",
+		Flags:     []cli.Flag{subscriptionNamespaceFlag()},
+		Action: func(c *cli.Context) error {
+			return setSuspend(c, "resumed", (*subscription.Manager).Resume)
+		},
+	}
+}
+
+func setSuspend(c *cli.Context, verb string, set func(*subscription.Manager, string, string) ([]string, error)) error {
+	if c.Args().Len() < 1 {
+		return fmt.Errorf("subscription name must be provided")
+	}
+	cl, err := buildK8sClient(c.String("kubeconfig"))
+	if err != nil {
+		return err
+	}
+	changed, err := set(subscription.NewManager(cl), c.String("namespace"), c.Args().First())
+	for _, name := range changed {
+		fmt.Printf("%s %s\n", name, verb)
+	}
+	return err
+}
+
+func subscriptionNamespaceFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:        "namespace",
+		DefaultText: "default",
+		Value:       "default",
+		Usage:       "The namespace the subscription is in",
+	}
+}
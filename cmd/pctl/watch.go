@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"github.com/weaveworks/pctl/pkg/subscription"
+	"k8s.io/client-go/dynamic"
+)
+
+// waitForReady is the only condition supported by --wait-for.
+const waitForReady = "ready"
+
+func watchFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "watch",
+			Aliases: []string{"w"},
+			Usage:   "Keep watching the subscriptions and print them again every time they change.",
+		},
+		&cli.StringFlag{
+			Name:  "wait-for",
+			Usage: "Wait until the subscriptions reach a condition, exiting with an error if they don't within --timeout. Supported conditions: ready.",
+		},
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Value: 5 * time.Minute,
+			Usage: "How long to wait with --wait-for.",
+		},
+	}
+}
+
+// watching returns whether --watch or --wait-for is set, and validates the latter.
+func watching(c *cli.Context) (bool, error) {
+	waitFor := c.String("wait-for")
+	if waitFor != "" && waitFor != waitForReady {
+		return false, fmt.Errorf("unsupported --wait-for condition %q, supported conditions: %s", waitFor, waitForReady)
+	}
+	return c.Bool("watch") || waitFor != "", nil
+}
+
+// watchSubscriptions prints the subscriptions every time they change until interrupted or, with --wait-for,
+// until they are ready.
+func watchSubscriptions(c *cli.Context, opts subscription.ListOptions, print func([]subscription.SubscriptionSummary) error) error {
+	config, err := buildRESTConfig(c.String("kubeconfig"), "")
+	if err != nil {
+		return err
+	}
+	dClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+	watcher := subscription.NewWatcher(dClient)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if c.String("wait-for") == "" {
+		err := watcher.Watch(ctx, opts, func(summaries []subscription.SubscriptionSummary) (bool, error) {
+			return false, print(summaries)
+		})
+		if err == context.Canceled {
+			return nil
+		}
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Duration("timeout"))
+	defer cancel()
+	var printErr error
+	if err := watcher.WaitForReady(ctx, opts, func(summaries []subscription.SubscriptionSummary) {
+		if printErr == nil {
+			printErr = print(summaries)
+		}
+	}); err != nil {
+		return err
+	}
+	return printErr
+}
@@ -3,6 +3,7 @@ module github.com/weaveworks/pctl
 go 1.16
 
 require (
+	github.com/Masterminds/semver/v3 v3.1.1
 	github.com/fluxcd/helm-controller/api v0.10.1
 	github.com/fluxcd/kustomize-controller/api v0.12.0
 	github.com/fluxcd/source-controller/api v0.12.2
@@ -13,10 +14,12 @@ require (
 	github.com/onsi/gomega v1.12.0
 	github.com/urfave/cli/v2 v2.3.0
 	github.com/weaveworks/profiles v0.0.0-20210517084335-30e9c5deb17c
+	golang.org/x/crypto v0.0.0-20201002170205-7f63de1d35b0
 	k8s.io/api v0.20.5
 	k8s.io/apiextensions-apiserver v0.20.5
 	k8s.io/apimachinery v0.20.5
 	k8s.io/client-go v0.20.5
 	sigs.k8s.io/cli-utils v0.25.0
 	sigs.k8s.io/controller-runtime v0.8.3
+	sigs.k8s.io/yaml v1.2.0
 )
@@ -0,0 +1,43 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/weaveworks/pctl/pkg/cluster"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// Apply applies the artifacts and the subscription generated by an installation directly to the cluster with
+// server-side apply, for clusters which aren't managed with a GitOps repository.
+func Apply(kClient client.Client, summary InstallSummary) error {
+	filenames := make([]string, 0, len(summary.Artifacts)+1)
+	for _, a := range summary.Artifacts {
+		filenames = append(filenames, a.Filename)
+	}
+	filenames = append(filenames, "profile.yaml")
+
+	for _, filename := range filenames {
+		content, err := ioutil.ReadFile(filepath.Join(summary.Directory, filename))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(content, &obj.Object); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", filename, err)
+		}
+		// the generated files contain empty fields which are set by the cluster
+		unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+		unstructured.RemoveNestedField(obj.Object, "status")
+		if err := kClient.Patch(context.Background(), obj, client.Apply, client.FieldOwner(cluster.FieldManager), client.ForceOwnership); err != nil {
+			return fmt.Errorf("failed to apply %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		fmt.Printf("%s/%s applied\n", strings.ToLower(obj.GetKind()), obj.GetName())
+	}
+	return nil
+}
@@ -2,8 +2,11 @@ package catalog
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
 	"github.com/weaveworks/pctl/pkg/git"
@@ -12,6 +15,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	kjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"sigs.k8s.io/yaml"
 )
 
 // InstallConfig defines parameters for the installation call.
@@ -27,6 +31,37 @@ type InstallConfig struct {
 	Directory     string
 }
 
+// InstallSummary describes the profile subscription and the artifacts generated by an installation.
+type InstallSummary struct {
+	CatalogName string
+	ProfileName string
+	Version     string
+	// VersionConstraint is the semver constraint Version was resolved from, if one was given.
+	VersionConstraint string
+	// PreviousVersion is set if the installation replaced a subscription with a different version.
+	PreviousVersion string
+	SubName         string
+	Namespace       string
+	Directory       string
+	Artifacts       []ArtifactSummary
+}
+
+// ArtifactSummary describes a single generated artifact.
+type ArtifactSummary struct {
+	Kind     string
+	Name     string
+	Filename string
+}
+
+// VersionChange returns the installed version, or the change between the previous and the installed
+// version in the form of old → new.
+func (s InstallSummary) VersionChange() string {
+	if s.PreviousVersion == "" || s.PreviousVersion == s.Version {
+		return s.Version
+	}
+	return fmt.Sprintf("%s → %s", s.PreviousVersion, s.Version)
+}
+
 //MakeArtifacts returns artifacts for a subscription
 type MakeArtifacts func(sub profilesv1.ProfileSubscription) ([]runtime.Object, error)
 
@@ -34,10 +69,18 @@ var makeArtifacts = profile.MakeArtifacts
 
 // Install using the catalog at catalogURL and a profile matching the provided profileName generates a profile subscription
 // and its artifacts
-func Install(cfg InstallConfig) error {
-	profile, err := Show(cfg.CatalogClient, cfg.CatalogName, cfg.ProfileName, cfg.Version)
+func Install(cfg InstallConfig) (InstallSummary, error) {
+	version, constraint := cfg.Version, ""
+	if IsVersionConstraint(cfg.Version) {
+		constraint = cfg.Version
+		var err error
+		if version, err = ResolveVersion(cfg.CatalogClient, cfg.CatalogName, cfg.ProfileName, constraint); err != nil {
+			return InstallSummary{}, err
+		}
+	}
+	profile, err := Show(cfg.CatalogClient, cfg.CatalogName, cfg.ProfileName, version)
 	if err != nil {
-		return fmt.Errorf("failed to get profile %q in catalog %q: %w", cfg.ProfileName, cfg.CatalogName, err)
+		return InstallSummary{}, fmt.Errorf("failed to get profile %q in catalog %q: %w", cfg.ProfileName, cfg.CatalogName, err)
 	}
 
 	subscription := profilesv1.ProfileSubscription{
@@ -54,6 +97,9 @@ func Install(cfg InstallConfig) error {
 			Version:    filepath.Join(profile.Name, profile.Version),
 		},
 	}
+	if constraint != "" {
+		subscription.Annotations = map[string]string{VersionConstraintAnnotation: constraint}
+	}
 	if cfg.ConfigMap != "" {
 		subscription.Spec.ValuesFrom = []helmv2.ValuesReference{
 			{
@@ -66,14 +112,28 @@ func Install(cfg InstallConfig) error {
 
 	artifacts, err := makeArtifacts(subscription)
 	if err != nil {
-		return fmt.Errorf("failed to generate artifacts: %w", err)
+		return InstallSummary{}, fmt.Errorf("failed to generate artifacts: %w", err)
 	}
 
 	e := kjson.NewSerializerWithOptions(kjson.DefaultMetaFactory, nil, nil, kjson.SerializerOptions{Yaml: true, Strict: true})
 	directory := filepath.Join(cfg.Directory, profile.Name)
-	err = os.Mkdir(directory, 0777)
-	if err != nil {
-		return fmt.Errorf("failed to create directory")
+	if err := os.Mkdir(directory, 0777); err != nil && !os.IsExist(err) {
+		return InstallSummary{}, fmt.Errorf("failed to create directory")
+	}
+
+	summary := InstallSummary{
+		CatalogName:       cfg.CatalogName,
+		ProfileName:       profile.Name,
+		Version:           profile.Version,
+		VersionConstraint: constraint,
+		PreviousVersion:   previousVersion(filepath.Join(directory, "profile.yaml")),
+		SubName:           cfg.SubName,
+		Namespace:         cfg.Namespace,
+		Directory:         directory,
+	}
+	// the artifacts are named by their position, so ones the profile no longer has would be left behind
+	if err := removeArtifacts(directory); err != nil {
+		return InstallSummary{}, err
 	}
 
 	generateOutput := func(filename string, o runtime.Object) error {
@@ -93,13 +153,61 @@ func Install(cfg InstallConfig) error {
 	}
 
 	for i, a := range artifacts {
-		filename := fmt.Sprintf("%s-%d.%s", a.GetObjectKind().GroupVersionKind().Kind, i, "yaml")
+		kind := a.GetObjectKind().GroupVersionKind().Kind
+		filename := fmt.Sprintf("%s-%d.%s", kind, i, "yaml")
 		if err := generateOutput(filename, a); err != nil {
-			return err
+			return InstallSummary{}, err
 		}
+		artifact := ArtifactSummary{
+			Kind:     kind,
+			Filename: filename,
+		}
+		if o, ok := a.(metav1.Object); ok {
+			artifact.Name = o.GetName()
+		}
+		summary.Artifacts = append(summary.Artifacts, artifact)
 	}
 
-	return generateOutput("profile.yaml", &subscription)
+	if err := generateOutput("profile.yaml", &subscription); err != nil {
+		return InstallSummary{}, err
+	}
+	return summary, nil
+}
+
+// artifactFilename matches the names of the artifact files generated by Install, such as HelmRelease-0.yaml.
+var artifactFilename = regexp.MustCompile(`^[A-Za-z]+-[0-9]+\.yaml$`)
+
+// removeArtifacts removes the artifact files generated by a previous installation into directory. Other files,
+// such as ones added by the user, are kept.
+func removeArtifacts(directory string) error {
+	entries, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", directory, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !artifactFilename.MatchString(entry.Name()) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(directory, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove previous artifact %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+// previousVersion returns the profile version of an already existing subscription file. Any
+// error is ignored, since in that case there is nothing to compare against.
+func previousVersion(filename string) string {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return ""
+	}
+	var sub profilesv1.ProfileSubscription
+	if err := yaml.Unmarshal(content, &sub); err != nil {
+		return ""
+	}
+	parts := strings.Split(sub.Spec.Version, "/")
+	return parts[len(parts)-1]
 }
 
 // CreatePullRequest creates a pull request from the current changes.
@@ -124,8 +232,21 @@ func CreatePullRequest(scm git.SCMClient, g git.Git) error {
 		return fmt.Errorf("failed to push changes: %w", err)
 	}
 
-	if err := scm.CreatePullRequest(); err != nil {
+	number, err := scm.CreatePullRequest()
+	if err != nil {
 		return fmt.Errorf("failed to create pull request: %w", err)
 	}
+
+	if err := scm.AddLabels(number); err != nil {
+		return fmt.Errorf("failed to add labels to pull request: %w", err)
+	}
+
+	if err := scm.RequestReviews(number); err != nil {
+		return fmt.Errorf("failed to request reviews on pull request: %w", err)
+	}
+
+	if err := scm.AddAssignees(number); err != nil {
+		return fmt.Errorf("failed to add assignees to pull request: %w", err)
+	}
 	return nil
 }
@@ -1,21 +1,27 @@
 package catalog_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
 	"github.com/weaveworks/pctl/pkg/catalog"
 	"github.com/weaveworks/pctl/pkg/catalog/fakes"
+	"github.com/weaveworks/pctl/pkg/git"
 	gitfakes "github.com/weaveworks/pctl/pkg/git/fakes"
+	"github.com/weaveworks/pctl/pkg/runner"
 	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
 )
 
@@ -89,8 +95,23 @@ var _ = Describe("Install", func() {
 
 	Describe("install", func() {
 		It("generates the artifacts", func() {
-			err := catalog.Install(cfg)
+			summary, err := catalog.Install(cfg)
 			Expect(err).NotTo(HaveOccurred())
+			Expect(summary).To(Equal(catalog.InstallSummary{
+				CatalogName: "nginx",
+				ProfileName: "nginx-1",
+				Version:     "v0.0.1",
+				SubName:     "mysub",
+				Namespace:   "default",
+				Directory:   filepath.Join(tempDir, "nginx-1"),
+				Artifacts: []catalog.ArtifactSummary{
+					{
+						Kind:     "kustomize",
+						Name:     "foo",
+						Filename: "kustomize-0.yaml",
+					},
+				},
+			}))
 
 			var files []string
 			profileDir := filepath.Join(tempDir, "nginx-1")
@@ -144,7 +165,7 @@ status: {}
 			})
 
 			It("errors", func() {
-				err := catalog.Install(cfg)
+				_, err := catalog.Install(cfg)
 				Expect(err).To(MatchError("failed to generate artifacts: foo"))
 			})
 		})
@@ -155,10 +176,176 @@ status: {}
 			})
 
 			It("errors", func() {
-				err := catalog.Install(cfg)
+				_, err := catalog.Install(cfg)
 				Expect(err).To(MatchError(ContainSubstring("failed to create directory")))
 			})
 		})
+
+		When("the profile was already installed with a different version", func() {
+			BeforeEach(func() {
+				profileDir := filepath.Join(tempDir, "nginx-1")
+				Expect(os.Mkdir(profileDir, 0777)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(profileDir, "profile.yaml"), []byte(`apiVersion: weave.works/v1alpha1
+kind: ProfileSubscription
+metadata:
+  name: mysub
+  namespace: default
+spec:
+  profileURL: https://github.com/weaveworks/nginx-profile
+  version: nginx-1/v0.0.0
+`), 0644)).To(Succeed())
+			})
+
+			It("overwrites the artifacts and records the previous version", func() {
+				summary, err := catalog.Install(cfg)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summary.PreviousVersion).To(Equal("v0.0.0"))
+				Expect(summary.VersionChange()).To(Equal("v0.0.0 → v0.0.1"))
+
+				content, err := ioutil.ReadFile(filepath.Join(tempDir, "nginx-1", "profile.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("version: nginx-1/v0.0.1"))
+			})
+		})
+
+		When("the profile is installed again with fewer artifacts", func() {
+			var artifacts []runtime.Object
+
+			BeforeEach(func() {
+				artifacts = nil
+				for _, name := range []string{"foo", "bar", "baz"} {
+					artifacts = append(artifacts, &kustomizev1.Kustomization{
+						ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+						TypeMeta:   metav1.TypeMeta{Kind: "Kustomization", APIVersion: "api"},
+					})
+				}
+				fakeMakeArtifacts = func(sub profilesv1.ProfileSubscription) ([]runtime.Object, error) {
+					return artifacts, nil
+				}
+			})
+
+			It("removes the artifacts which are no longer generated and keeps other files", func() {
+				_, err := catalog.Install(cfg)
+				Expect(err).NotTo(HaveOccurred())
+				profileDir := filepath.Join(tempDir, "nginx-1")
+				Expect(ioutil.WriteFile(filepath.Join(profileDir, "values.yaml"), []byte("replicas: 2\n"), 0644)).To(Succeed())
+
+				artifacts = artifacts[:1]
+				summary, err := catalog.Install(cfg)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summary.Artifacts).To(HaveLen(1))
+
+				entries, err := ioutil.ReadDir(profileDir)
+				Expect(err).NotTo(HaveOccurred())
+				var files []string
+				for _, entry := range entries {
+					files = append(files, entry.Name())
+				}
+				Expect(files).To(ConsistOf("Kustomization-0.yaml", "profile.yaml", "values.yaml"))
+			})
+
+			It("commits the removed artifacts as deletions", func() {
+				Expect(exec.Command("git", "init", "-q", tempDir).Run()).To(Succeed())
+				g := git.NewCLIGit(git.CLIGitConfig{
+					Paths:       []string{"nginx-1"},
+					Location:    tempDir,
+					AuthorName:  "pctl",
+					AuthorEmail: "pctl@example.com",
+				}, &runner.CLIRunner{})
+				_, err := catalog.Install(cfg)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(g.Add()).To(Succeed())
+				Expect(g.Commit()).To(Succeed())
+
+				artifacts = artifacts[:1]
+				_, err = catalog.Install(cfg)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(g.Add()).To(Succeed())
+				Expect(g.Commit()).To(Succeed())
+
+				out, err := exec.Command("git", "-C", tempDir, "show", "--name-status", "--format=", "HEAD").Output()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(out)).To(Equal("D\tnginx-1/Kustomization-1.yaml\nD\tnginx-1/Kustomization-2.yaml\n"))
+			})
+		})
+
+		When("a version constraint is given", func() {
+			BeforeEach(func() {
+				cfg.Version = "~0.0.1"
+				fakeCatalogClient.DoRequestReturnsOnCall(0, []byte(`[
+	{"name": "nginx-1", "catalog": "nginx", "version": "v0.0.1"},
+	{"name": "nginx-1", "catalog": "nginx", "version": "v0.1.0"},
+	{"name": "nginx-1", "catalog": "nginx", "version": "v0.0.2-rc.1"}
+]`), 200, nil)
+				fakeCatalogClient.DoRequestReturnsOnCall(1, httpBody, 200, nil)
+			})
+
+			It("installs the newest version satisfying it and records the constraint in an annotation", func() {
+				summary, err := catalog.Install(cfg)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summary.Version).To(Equal("v0.0.1"))
+				Expect(summary.VersionConstraint).To(Equal("~0.0.1"))
+
+				Expect(fakeCatalogClient.DoRequestCallCount()).To(Equal(2))
+				path, query := fakeCatalogClient.DoRequestArgsForCall(0)
+				Expect(path).To(Equal("/profiles"))
+				Expect(query).To(Equal(map[string]string{"name": "nginx-1"}))
+				path, _ = fakeCatalogClient.DoRequestArgsForCall(1)
+				Expect(path).To(Equal("/profiles/nginx/nginx-1/v0.0.1"))
+
+				content, err := ioutil.ReadFile(filepath.Join(tempDir, "nginx-1", "profile.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring(`  annotations:
+    pctl.weave.works/version-constraint: ~0.0.1
+`))
+				Expect(string(content)).To(ContainSubstring("version: nginx-1/v0.0.1"))
+			})
+
+			When("no version satisfies it", func() {
+				BeforeEach(func() {
+					cfg.Version = ">=1.0 <2.0"
+				})
+
+				It("errors", func() {
+					_, err := catalog.Install(cfg)
+					Expect(err).To(MatchError(`no version of profile "nginx-1" in catalog "nginx" satisfies ">=1.0 <2.0"`))
+				})
+			})
+		})
+	})
+
+	Describe("apply", func() {
+		It("applies the artifacts and the subscription with server-side apply", func() {
+			summary, err := catalog.Install(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			k8sClient := &applyClient{}
+			Expect(catalog.Apply(k8sClient, summary)).To(Succeed())
+			Expect(k8sClient.applied).To(HaveLen(2))
+			Expect(k8sClient.applied[0].GetKind()).To(Equal("kustomize"))
+			Expect(k8sClient.applied[1].Object).To(Equal(map[string]interface{}{
+				"apiVersion": "weave.works/v1alpha1",
+				"kind":       "ProfileSubscription",
+				"metadata": map[string]interface{}{
+					"name":      "mysub",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"profileURL": "https://github.com/weaveworks/nginx-profile",
+					"version":    "nginx-1/v0.0.1",
+				},
+			}))
+			force := true
+			Expect(k8sClient.opts).To(Equal(&client.PatchOptions{FieldManager: "pctl", Force: &force}))
+		})
+
+		When("applying fails", func() {
+			It("errors", func() {
+				summary, err := catalog.Install(cfg)
+				Expect(err).NotTo(HaveOccurred())
+				err = catalog.Apply(&applyClient{err: errors.New("nope")}, summary)
+				Expect(err).To(MatchError("failed to apply kustomize foo: nope"))
+			})
+		})
 	})
 
 	Describe("create-pr", func() {
@@ -172,6 +359,17 @@ status: {}
 				Expect(fakeGit.PushCallCount()).To(Equal(1))
 				Expect(fakeScm.CreatePullRequestCallCount()).To(Equal(1))
 			})
+			It("applies labels, reviewers and assignees to the created pull request", func() {
+				fakeScm.CreatePullRequestReturns(42, nil)
+				err := catalog.CreatePullRequest(fakeScm, fakeGit)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeScm.AddLabelsCallCount()).To(Equal(1))
+				Expect(fakeScm.AddLabelsArgsForCall(0)).To(Equal(42))
+				Expect(fakeScm.RequestReviewsCallCount()).To(Equal(1))
+				Expect(fakeScm.RequestReviewsArgsForCall(0)).To(Equal(42))
+				Expect(fakeScm.AddAssigneesCallCount()).To(Equal(1))
+				Expect(fakeScm.AddAssigneesArgsForCall(0)).To(Equal(42))
+			})
 		})
 		When("create-pr is set to true but something goes wrong", func() {
 			It("handles create branch errors", func() {
@@ -195,10 +393,42 @@ status: {}
 				Expect(err).To(MatchError("failed to push changes: nope"))
 			})
 			It("handles create pull request errors", func() {
-				fakeScm.CreatePullRequestReturns(errors.New("nope"))
+				fakeScm.CreatePullRequestReturns(0, errors.New("nope"))
 				err := catalog.CreatePullRequest(fakeScm, fakeGit)
 				Expect(err).To(MatchError("failed to create pull request: nope"))
 			})
+			It("handles add labels errors", func() {
+				fakeScm.AddLabelsReturns(errors.New("nope"))
+				err := catalog.CreatePullRequest(fakeScm, fakeGit)
+				Expect(err).To(MatchError("failed to add labels to pull request: nope"))
+			})
+			It("handles request reviews errors", func() {
+				fakeScm.RequestReviewsReturns(errors.New("nope"))
+				err := catalog.CreatePullRequest(fakeScm, fakeGit)
+				Expect(err).To(MatchError("failed to request reviews on pull request: nope"))
+			})
+			It("handles add assignees errors", func() {
+				fakeScm.AddAssigneesReturns(errors.New("nope"))
+				err := catalog.CreatePullRequest(fakeScm, fakeGit)
+				Expect(err).To(MatchError("failed to add assignees to pull request: nope"))
+			})
 		})
 	})
 })
+
+// applyClient records server-side applies, which the fake client doesn't support.
+type applyClient struct {
+	client.Client
+	applied []*unstructured.Unstructured
+	opts    *client.PatchOptions
+	err     error
+}
+
+func (c *applyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.applied = append(c.applied, obj.(*unstructured.Unstructured))
+	c.opts = (&client.PatchOptions{}).ApplyOptions(opts)
+	return nil
+}
@@ -0,0 +1,79 @@
+package catalog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const (
+	// DefaultPullRequestTitleTemplate is the template used for the title of a pull request if none is provided.
+	DefaultPullRequestTitleTemplate = `pctl: install profile {{ .CatalogName }}/{{ .ProfileName }} {{ .VersionChange }}`
+	// DefaultPullRequestBodyTemplate is the template used for the body of a pull request if none is provided.
+	DefaultPullRequestBodyTemplate = `This pull request was generated by pctl.
+
+- Profile: {{ .ProfileName }}
+- Catalog: {{ .CatalogName }}
+- Version: {{ .VersionChange }}
+- Subscription: {{ .SubName }}
+- Namespace: {{ .Namespace }}
+
+Generated artifacts:
+{{ range .Artifacts }}
+- {{ .Kind }} {{ .Name }} ({{ .Filename }})
+{{- end }}
+`
+	// DefaultCommitMessageTemplate is the template used for the commit message if none is provided.
+	DefaultCommitMessageTemplate = `Install profile {{ .CatalogName }}/{{ .ProfileName }} {{ .VersionChange }}
+
+Subscription {{ .SubName }} in namespace {{ .Namespace }}.`
+)
+
+// MessageTemplates contains Go templates for the messages which describe a change. Empty templates
+// are replaced with their defaults.
+type MessageTemplates struct {
+	PullRequestTitle string
+	PullRequestBody  string
+	CommitMessage    string
+}
+
+// Messages contains the rendered messages which describe a change.
+type Messages struct {
+	PullRequestTitle string
+	PullRequestBody  string
+	CommitMessage    string
+}
+
+// RenderMessages renders the pull request title, body and commit message using the summary of an installation.
+func RenderMessages(templates MessageTemplates, summary InstallSummary) (Messages, error) {
+	var (
+		messages Messages
+		err      error
+	)
+	if messages.PullRequestTitle, err = render("pull request title", templates.PullRequestTitle, DefaultPullRequestTitleTemplate, summary); err != nil {
+		return Messages{}, err
+	}
+	if messages.PullRequestBody, err = render("pull request body", templates.PullRequestBody, DefaultPullRequestBodyTemplate, summary); err != nil {
+		return Messages{}, err
+	}
+	if messages.CommitMessage, err = render("commit message", templates.CommitMessage, DefaultCommitMessageTemplate, summary); err != nil {
+		return Messages{}, err
+	}
+	return messages, nil
+}
+
+func render(name, text, defaultText string, data interface{}) (string, error) {
+	if text == "" {
+		text = defaultText
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s template: %w", name, err)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,91 @@
+package catalog_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/pctl/pkg/catalog"
+)
+
+var _ = Describe("RenderMessages", func() {
+	var summary catalog.InstallSummary
+
+	BeforeEach(func() {
+		summary = catalog.InstallSummary{
+			CatalogName: "nginx-catalog",
+			ProfileName: "weaveworks-nginx",
+			Version:     "v0.1.0",
+			SubName:     "mysub",
+			Namespace:   "default",
+			Artifacts: []catalog.ArtifactSummary{
+				{Kind: "GitRepository", Name: "mysub-profiles-examples-v0.1.0", Filename: "GitRepository-0.yaml"},
+				{Kind: "HelmRelease", Name: "mysub-weaveworks-nginx-nginx-server", Filename: "HelmRelease-1.yaml"},
+			},
+		}
+	})
+
+	It("renders the default templates", func() {
+		messages, err := catalog.RenderMessages(catalog.MessageTemplates{}, summary)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(messages.PullRequestTitle).To(Equal("pctl: install profile nginx-catalog/weaveworks-nginx v0.1.0"))
+		Expect(messages.PullRequestBody).To(Equal(`This pull request was generated by pctl.
+
+- Profile: weaveworks-nginx
+- Catalog: nginx-catalog
+- Version: v0.1.0
+- Subscription: mysub
+- Namespace: default
+
+Generated artifacts:
+
+- GitRepository mysub-profiles-examples-v0.1.0 (GitRepository-0.yaml)
+- HelmRelease mysub-weaveworks-nginx-nginx-server (HelmRelease-1.yaml)`))
+		Expect(messages.CommitMessage).To(Equal(`Install profile nginx-catalog/weaveworks-nginx v0.1.0
+
+Subscription mysub in namespace default.`))
+	})
+
+	When("the version changed", func() {
+		It("summarizes the change", func() {
+			summary.PreviousVersion = "v0.0.1"
+			messages, err := catalog.RenderMessages(catalog.MessageTemplates{}, summary)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages.PullRequestTitle).To(Equal("pctl: install profile nginx-catalog/weaveworks-nginx v0.0.1 → v0.1.0"))
+			Expect(messages.PullRequestBody).To(ContainSubstring("- Version: v0.0.1 → v0.1.0"))
+		})
+	})
+
+	When("custom templates are provided", func() {
+		It("uses them instead of the defaults", func() {
+			messages, err := catalog.RenderMessages(catalog.MessageTemplates{
+				PullRequestTitle: "[{{ .Namespace }}] {{ .SubName }}",
+				PullRequestBody:  "{{ len .Artifacts }} artifacts",
+				CommitMessage:    "chore: bump {{ .ProfileName }}",
+			}, summary)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(Equal(catalog.Messages{
+				PullRequestTitle: "[default] mysub",
+				PullRequestBody:  "2 artifacts",
+				CommitMessage:    "chore: bump weaveworks-nginx",
+			}))
+		})
+	})
+
+	When("a template is invalid", func() {
+		It("returns an error", func() {
+			_, err := catalog.RenderMessages(catalog.MessageTemplates{
+				PullRequestTitle: "{{ .Nope",
+			}, summary)
+			Expect(err).To(MatchError(ContainSubstring("failed to parse pull request title template")))
+		})
+	})
+
+	When("a template refers to an unknown field", func() {
+		It("returns an error", func() {
+			_, err := catalog.RenderMessages(catalog.MessageTemplates{
+				CommitMessage: "{{ .Nope }}",
+			}, summary)
+			Expect(err).To(MatchError(ContainSubstring("failed to render commit message template")))
+		})
+	})
+})
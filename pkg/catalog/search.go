@@ -4,14 +4,54 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"regexp"
+	"sort"
+	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
 )
 
-// Search queries the catalog at catalogURL for profiles matching the provided searchName.
-func Search(catalogClient CatalogClient, searchName string) ([]profilesv1.ProfileDescription, error) {
+const (
+	// MatchSubstring matches profiles whose name contains the search term
+	MatchSubstring = "substring"
+	// MatchRegex matches profiles whose name matches the search term as a regular expression
+	MatchRegex = "regex"
+	// MatchFuzzy matches profiles whose name contains the characters of the search term in order
+	MatchFuzzy = "fuzzy"
+)
+
+// SearchOptions selects the profiles to search for. The catalog API only filters by name, so everything else
+// is filtered after fetching the profiles.
+type SearchOptions struct {
+	// Term is matched against the names of profiles. All profiles match an empty term.
+	Term string
+	// Match is how Term is matched: MatchSubstring, MatchRegex or MatchFuzzy. Defaults to MatchSubstring.
+	Match string
+	// Catalog only selects profiles of the catalog with this name.
+	Catalog string
+	// Maintainer only selects profiles whose maintainer contains it, ignoring case.
+	Maintainer string
+	// Tag only selects the profile release with this git tag, either <version> or <profile>/<version>.
+	Tag string
+	// Prerelease selects prerelease versions as well, which are left out otherwise.
+	Prerelease bool
+}
+
+// Search queries the catalog for profiles matching the search options. The profiles are ranked by how well
+// their name matches, best first.
+func Search(catalogClient CatalogClient, opts SearchOptions) ([]profilesv1.ProfileDescription, error) {
+	match, err := opts.matcher()
+	if err != nil {
+		return nil, err
+	}
+	// only substring matching is done by the catalog
+	name := opts.Term
+	if opts.Match != "" && opts.Match != MatchSubstring {
+		name = ""
+	}
 	q := map[string]string{
-		"name": searchName,
+		"name": name,
 	}
 	data, statusCode, err := catalogClient.DoRequest("/profiles", q)
 	if err != nil {
@@ -26,5 +66,151 @@ func Search(catalogClient CatalogClient, searchName string) ([]profilesv1.Profil
 		return nil, fmt.Errorf("failed to parse catalog: %w", err)
 	}
 
-	return profiles, nil
+	type result struct {
+		profile profilesv1.ProfileDescription
+		rank    int
+	}
+	var results []result
+	for _, p := range profiles {
+		if rank, ok := match(p.Name); ok && opts.selects(p) {
+			results = append(results, result{profile: p, rank: rank})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if a.profile.Name != b.profile.Name {
+			return a.profile.Name < b.profile.Name
+		}
+		if a.profile.CatalogSource != b.profile.CatalogSource {
+			return a.profile.CatalogSource < b.profile.CatalogSource
+		}
+		return newerVersion(a.profile.Version, b.profile.Version)
+	})
+	ranked := make([]profilesv1.ProfileDescription, 0, len(results))
+	for _, r := range results {
+		ranked = append(ranked, r.profile)
+	}
+	return ranked, nil
+}
+
+// Page returns the profiles on a page of the given size, counting from 1. A size of 0 returns all profiles.
+func Page(profiles []profilesv1.ProfileDescription, page, size int) []profilesv1.ProfileDescription {
+	if size <= 0 {
+		return profiles
+	}
+	if page < 1 {
+		page = 1
+	}
+	start := (page - 1) * size
+	if start >= len(profiles) {
+		return nil
+	}
+	end := start + size
+	if end > len(profiles) {
+		end = len(profiles)
+	}
+	return profiles[start:end]
+}
+
+// matcher returns a func matching a profile name against the term. It returns the rank of the match, lower is
+// better, and whether the name matched at all.
+func (o SearchOptions) matcher() (func(name string) (int, bool), error) {
+	if o.Term == "" {
+		return func(string) (int, bool) { return 0, true }, nil
+	}
+	switch o.Match {
+	case "", MatchSubstring:
+		return func(name string) (int, bool) {
+			switch {
+			case name == o.Term:
+				return 0, true
+			case strings.HasPrefix(name, o.Term):
+				return 1, true
+			case strings.Contains(name, o.Term):
+				return 2, true
+			}
+			return 0, false
+		}, nil
+	case MatchRegex:
+		re, err := regexp.Compile(o.Term)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", o.Term, err)
+		}
+		return func(name string) (int, bool) {
+			loc := re.FindStringIndex(name)
+			switch {
+			case loc == nil:
+				return 0, false
+			case loc[0] == 0 && loc[1] == len(name):
+				return 0, true
+			case loc[0] == 0:
+				return 1, true
+			}
+			return 2, true
+		}, nil
+	case MatchFuzzy:
+		return func(name string) (int, bool) {
+			return fuzzyMatch(strings.ToLower(name), strings.ToLower(o.Term))
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported match %q, must be one of: %s, %s, %s", o.Match, MatchSubstring, MatchRegex, MatchFuzzy)
+}
+
+// fuzzyMatch returns whether the characters of term appear in name in order. The rank is the least number of
+// characters of name skipped between the first and last of them, so closer matches rank better.
+func fuzzyMatch(name, term string) (int, bool) {
+	best, found := 0, false
+	for start := 0; start < len(name); start++ {
+		if name[start] != term[0] {
+			continue
+		}
+		gaps, t := 0, 1
+		for i := start + 1; i < len(name) && t < len(term); i++ {
+			if name[i] == term[t] {
+				t++
+			} else {
+				gaps++
+			}
+		}
+		if t < len(term) {
+			break
+		}
+		if !found || gaps < best {
+			best, found = gaps, true
+		}
+	}
+	return best, found
+}
+
+// selects returns whether a profile passes the filters other than the search term.
+func (o SearchOptions) selects(p profilesv1.ProfileDescription) bool {
+	if o.Catalog != "" && p.CatalogSource != o.Catalog {
+		return false
+	}
+	if o.Maintainer != "" && !strings.Contains(strings.ToLower(p.Maintainer), strings.ToLower(o.Maintainer)) {
+		return false
+	}
+	if o.Tag != "" && o.Tag != p.Version && o.Tag != p.Name+"/"+p.Version {
+		return false
+	}
+	return o.Prerelease || !isPrerelease(p.Version)
+}
+
+// isPrerelease returns whether a version is a semver prerelease, such as 0.2.0-rc.1.
+func isPrerelease(version string) bool {
+	v, err := semver.NewVersion(version)
+	return err == nil && v.Prerelease() != ""
+}
+
+// newerVersion returns whether version a is newer than b. Versions which aren't semver are compared as text.
+func newerVersion(a, b string) bool {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	if errA != nil || errB != nil {
+		return a > b
+	}
+	return va.GreaterThan(vb)
 }
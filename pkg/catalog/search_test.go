@@ -37,7 +37,7 @@ var _ = Describe("Search", func() {
 		  `)
 			fakeCatalogClient.DoRequestReturns(httpBody, 200, nil)
 
-			resp, err := catalog.Search(fakeCatalogClient, "nginx")
+			resp, err := catalog.Search(fakeCatalogClient, catalog.SearchOptions{Term: "nginx"})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(fakeCatalogClient.DoRequestCallCount()).To(Equal(1))
 			path, query := fakeCatalogClient.DoRequestArgsForCall(0)
@@ -61,7 +61,7 @@ var _ = Describe("Search", func() {
 	When("catalog client fails to make the request", func() {
 		It("returns an error", func() {
 			fakeCatalogClient.DoRequestReturns(nil, 502, fmt.Errorf("foo"))
-			_, err := catalog.Search(fakeCatalogClient, "dontexist")
+			_, err := catalog.Search(fakeCatalogClient, catalog.SearchOptions{Term: "dontexist"})
 			Expect(err).To(MatchError("failed to fetch catalog: foo"))
 		})
 	})
@@ -71,7 +71,7 @@ var _ = Describe("Search", func() {
 			httpBody := []byte(`[]`)
 			fakeCatalogClient.DoRequestReturns(httpBody, 500, nil)
 
-			_, err := catalog.Search(fakeCatalogClient, "dontexist")
+			_, err := catalog.Search(fakeCatalogClient, catalog.SearchOptions{Term: "dontexist"})
 			Expect(err).To(MatchError("failed to fetch profile from catalog, status code 500"))
 			path, query := fakeCatalogClient.DoRequestArgsForCall(0)
 			Expect(path).To(Equal("/profiles"))
@@ -86,9 +86,109 @@ var _ = Describe("Search", func() {
 			httpBody := []byte(`!20342 totally n:ot json "`)
 			fakeCatalogClient.DoRequestReturns(httpBody, 200, nil)
 
-			_, err := catalog.Search(fakeCatalogClient, "dontexist")
+			_, err := catalog.Search(fakeCatalogClient, catalog.SearchOptions{Term: "dontexist"})
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(ContainSubstring("failed to parse catalog")))
 		})
 	})
+
+	Context("filters and ranking", func() {
+		BeforeEach(func() {
+			httpBody := []byte(`
+[
+    {"name": "weaveworks-nginx", "catalog": "weaveworks", "version": "v0.1.0", "maintainer": "Weaveworks"},
+    {"name": "weaveworks-nginx", "catalog": "weaveworks", "version": "v0.2.0", "maintainer": "Weaveworks"},
+    {"name": "weaveworks-nginx", "catalog": "weaveworks", "version": "v0.3.0-rc.1", "maintainer": "Weaveworks"},
+    {"name": "nginx", "catalog": "community", "version": "v1.0.0", "maintainer": "someone"},
+    {"name": "ingress-nginx", "catalog": "community", "version": "v2.0.0", "maintainer": "someone"},
+    {"name": "nginx-ingress", "catalog": "community", "version": "v2.0.0", "maintainer": "someone"}
+]`)
+			fakeCatalogClient.DoRequestReturns(httpBody, 200, nil)
+		})
+
+		names := func(profiles []profilesv1.ProfileDescription) []string {
+			var names []string
+			for _, p := range profiles {
+				names = append(names, p.Name+"@"+p.Version)
+			}
+			return names
+		}
+
+		It("ranks exact matches first, then prefixes, then other matches, leaving out prereleases", func() {
+			resp, err := catalog.Search(fakeCatalogClient, catalog.SearchOptions{Term: "nginx"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names(resp)).To(Equal([]string{
+				"nginx@v1.0.0",
+				"nginx-ingress@v2.0.0",
+				"ingress-nginx@v2.0.0",
+				"weaveworks-nginx@v0.2.0",
+				"weaveworks-nginx@v0.1.0",
+			}))
+		})
+
+		It("includes prereleases if asked to", func() {
+			resp, err := catalog.Search(fakeCatalogClient, catalog.SearchOptions{Term: "weaveworks", Prerelease: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names(resp)).To(Equal([]string{
+				"weaveworks-nginx@v0.3.0-rc.1",
+				"weaveworks-nginx@v0.2.0",
+				"weaveworks-nginx@v0.1.0",
+			}))
+		})
+
+		It("filters by catalog, maintainer and tag", func() {
+			resp, err := catalog.Search(fakeCatalogClient, catalog.SearchOptions{Catalog: "community", Maintainer: "SOME"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names(resp)).To(Equal([]string{"ingress-nginx@v2.0.0", "nginx@v1.0.0", "nginx-ingress@v2.0.0"}))
+
+			resp, err = catalog.Search(fakeCatalogClient, catalog.SearchOptions{Tag: "weaveworks-nginx/v0.1.0"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names(resp)).To(Equal([]string{"weaveworks-nginx@v0.1.0"}))
+		})
+
+		It("matches regular expressions client-side", func() {
+			resp, err := catalog.Search(fakeCatalogClient, catalog.SearchOptions{Term: "^nginx", Match: catalog.MatchRegex})
+			Expect(err).NotTo(HaveOccurred())
+			_, query := fakeCatalogClient.DoRequestArgsForCall(0)
+			Expect(query).To(Equal(map[string]string{"name": ""}))
+			Expect(names(resp)).To(Equal([]string{"nginx@v1.0.0", "nginx-ingress@v2.0.0"}))
+		})
+
+		It("matches fuzzily, ranking closer matches first", func() {
+			resp, err := catalog.Search(fakeCatalogClient, catalog.SearchOptions{Term: "ngx", Match: catalog.MatchFuzzy})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names(resp)).To(Equal([]string{
+				"ingress-nginx@v2.0.0",
+				"nginx@v1.0.0",
+				"nginx-ingress@v2.0.0",
+				"weaveworks-nginx@v0.2.0",
+				"weaveworks-nginx@v0.1.0",
+			}))
+		})
+
+		When("the regular expression is invalid", func() {
+			It("returns an error", func() {
+				_, err := catalog.Search(fakeCatalogClient, catalog.SearchOptions{Term: "(", Match: catalog.MatchRegex})
+				Expect(err).To(MatchError(ContainSubstring(`invalid regular expression "("`)))
+			})
+		})
+
+		When("the match is unsupported", func() {
+			It("returns an error", func() {
+				_, err := catalog.Search(fakeCatalogClient, catalog.SearchOptions{Term: "nginx", Match: "glob"})
+				Expect(err).To(MatchError("unsupported match \"glob\", must be one of: substring, regex, fuzzy"))
+			})
+		})
+	})
+
+	Context("Page", func() {
+		profiles := []profilesv1.ProfileDescription{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+		It("returns the profiles on the page", func() {
+			Expect(catalog.Page(profiles, 1, 2)).To(Equal(profiles[:2]))
+			Expect(catalog.Page(profiles, 2, 2)).To(Equal(profiles[2:]))
+			Expect(catalog.Page(profiles, 3, 2)).To(BeEmpty())
+			Expect(catalog.Page(profiles, 1, 0)).To(Equal(profiles))
+		})
+	})
 })
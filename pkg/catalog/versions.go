@@ -0,0 +1,118 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+)
+
+// VersionConstraintAnnotation records the version constraint a subscription was installed with, so upgrades can
+// stay within it.
+const VersionConstraintAnnotation = "pctl.weave.works/version-constraint"
+
+// ProfileVersion describes a version of a profile listed in a catalog
+type ProfileVersion struct {
+	Version    string `json:"version"`
+	Prerelease bool   `json:"prerelease"`
+	// Installed is set by MarkInstalled for the version of an installed subscription
+	Installed bool `json:"installed"`
+}
+
+// Versions queries the catalog for all versions of a profile, newest first. Versions which aren't semver are
+// listed after the others.
+func Versions(catalogClient CatalogClient, catalogName, profileName string) ([]ProfileVersion, error) {
+	data, statusCode, err := catalogClient.DoRequest("/profiles", map[string]string{"name": profileName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch catalog: %w", err)
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch profile from catalog, status code %d", statusCode)
+	}
+	var profiles []profilesv1.ProfileDescription
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog: %w", err)
+	}
+
+	var (
+		semvers []*semver.Version
+		others  []string
+		seen    = map[string]bool{}
+	)
+	for _, p := range profiles {
+		if p.CatalogSource != catalogName || p.Name != profileName || seen[p.Version] {
+			continue
+		}
+		seen[p.Version] = true
+		if v, err := semver.NewVersion(p.Version); err == nil {
+			semvers = append(semvers, v)
+		} else {
+			others = append(others, p.Version)
+		}
+	}
+	if len(semvers) == 0 && len(others) == 0 {
+		return nil, fmt.Errorf("unable to find profile %q in catalog %q", profileName, catalogName)
+	}
+	sort.Sort(sort.Reverse(semver.Collection(semvers)))
+	sort.Strings(others)
+
+	var versions []ProfileVersion
+	for _, v := range semvers {
+		versions = append(versions, ProfileVersion{Version: v.Original(), Prerelease: v.Prerelease() != ""})
+	}
+	for _, v := range others {
+		versions = append(versions, ProfileVersion{Version: v})
+	}
+	return versions, nil
+}
+
+// MarkInstalled marks the version of the subscription generated by install in directory as installed. It
+// returns the installed version, or an empty string if there is no subscription in directory.
+func MarkInstalled(versions []ProfileVersion, directory string) string {
+	installed := previousVersion(filepath.Join(directory, "profile.yaml"))
+	if installed == "" {
+		return ""
+	}
+	for i := range versions {
+		versions[i].Installed = versions[i].Version == installed
+	}
+	return installed
+}
+
+// IsVersionConstraint returns whether version is a semver constraint, such as ~0.3, ^1.2.0, >=1.0 <2.0 or 1.x,
+// rather than an exact version.
+func IsVersionConstraint(version string) bool {
+	if strings.ContainsAny(version, "~^<>=!*, |") {
+		return true
+	}
+	for _, part := range strings.Split(strings.TrimPrefix(version, "v"), ".") {
+		if part == "x" || part == "X" {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveVersion returns the newest version of a profile in the catalog which satisfies a semver constraint.
+// Prereleases are only considered if the constraint contains one, such as >=1.0.0-rc.1.
+func ResolveVersion(catalogClient CatalogClient, catalogName, profileName, constraint string) (string, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return "", fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+	}
+	versions, err := Versions(catalogClient, catalogName, profileName)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range versions {
+		if sv, err := semver.NewVersion(v.Version); err == nil && c.Check(sv) {
+			return v.Version, nil
+		}
+	}
+	return "", fmt.Errorf("no version of profile %q in catalog %q satisfies %q", profileName, catalogName, constraint)
+}
@@ -0,0 +1,128 @@
+package catalog_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/pctl/pkg/catalog"
+	"github.com/weaveworks/pctl/pkg/catalog/fakes"
+)
+
+var _ = Describe("Versions", func() {
+	var (
+		fakeCatalogClient *fakes.FakeCatalogClient
+	)
+
+	BeforeEach(func() {
+		fakeCatalogClient = new(fakes.FakeCatalogClient)
+		httpBody := []byte(`
+[
+    {"name": "nginx", "catalog": "weaveworks", "version": "v0.1.0"},
+    {"name": "nginx", "catalog": "weaveworks", "version": "v0.10.0"},
+    {"name": "nginx", "catalog": "weaveworks", "version": "v0.2.0"},
+    {"name": "nginx", "catalog": "weaveworks", "version": "v0.11.0-rc.1"},
+    {"name": "nginx", "catalog": "weaveworks", "version": "latest"},
+    {"name": "nginx", "catalog": "other", "version": "v9.0.0"},
+    {"name": "nginx-ingress", "catalog": "weaveworks", "version": "v8.0.0"}
+]`)
+		fakeCatalogClient.DoRequestReturns(httpBody, 200, nil)
+	})
+
+	It("returns the versions of the profile sorted by semver, newest first", func() {
+		versions, err := catalog.Versions(fakeCatalogClient, "weaveworks", "nginx")
+		Expect(err).NotTo(HaveOccurred())
+		path, query := fakeCatalogClient.DoRequestArgsForCall(0)
+		Expect(path).To(Equal("/profiles"))
+		Expect(query).To(Equal(map[string]string{"name": "nginx"}))
+		Expect(versions).To(Equal([]catalog.ProfileVersion{
+			{Version: "v0.11.0-rc.1", Prerelease: true},
+			{Version: "v0.10.0"},
+			{Version: "v0.2.0"},
+			{Version: "v0.1.0"},
+			{Version: "latest"},
+		}))
+	})
+
+	When("the profile doesn't exist", func() {
+		It("returns an error", func() {
+			_, err := catalog.Versions(fakeCatalogClient, "weaveworks", "dontexist")
+			Expect(err).To(MatchError(`unable to find profile "dontexist" in catalog "weaveworks"`))
+		})
+	})
+
+	When("the catalog client fails to make the request", func() {
+		It("returns an error", func() {
+			fakeCatalogClient.DoRequestReturns(nil, 502, fmt.Errorf("foo"))
+			_, err := catalog.Versions(fakeCatalogClient, "weaveworks", "nginx")
+			Expect(err).To(MatchError("failed to fetch catalog: foo"))
+		})
+	})
+
+	Context("MarkInstalled", func() {
+		var tempDir string
+
+		BeforeEach(func() {
+			var err error
+			tempDir, err = ioutil.TempDir("", "catalog-versions")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(tempDir)
+		})
+
+		It("marks the version of the subscription in the directory", func() {
+			Expect(ioutil.WriteFile(filepath.Join(tempDir, "profile.yaml"), []byte(`apiVersion: weave.works/v1alpha1
+kind: ProfileSubscription
+metadata:
+  name: pctl-profile
+spec:
+  profileURL: https://github.com/weaveworks/nginx-profile
+  version: nginx/v0.2.0
+`), 0644)).To(Succeed())
+			versions := []catalog.ProfileVersion{{Version: "v0.10.0"}, {Version: "v0.2.0"}}
+			Expect(catalog.MarkInstalled(versions, tempDir)).To(Equal("v0.2.0"))
+			Expect(versions).To(Equal([]catalog.ProfileVersion{{Version: "v0.10.0"}, {Version: "v0.2.0", Installed: true}}))
+		})
+
+		When("there is no subscription in the directory", func() {
+			It("marks nothing", func() {
+				versions := []catalog.ProfileVersion{{Version: "v0.2.0"}}
+				Expect(catalog.MarkInstalled(versions, tempDir)).To(BeEmpty())
+				Expect(versions).To(Equal([]catalog.ProfileVersion{{Version: "v0.2.0"}}))
+			})
+		})
+	})
+
+	Context("ResolveVersion", func() {
+		It("returns the newest version satisfying the constraint", func() {
+			Expect(catalog.ResolveVersion(fakeCatalogClient, "weaveworks", "nginx", "^0.2")).To(Equal("v0.2.0"))
+			Expect(catalog.ResolveVersion(fakeCatalogClient, "weaveworks", "nginx", ">=0.1.0 <1.0.0")).To(Equal("v0.10.0"))
+		})
+
+		It("only considers prereleases if the constraint contains one", func() {
+			Expect(catalog.ResolveVersion(fakeCatalogClient, "weaveworks", "nginx", ">=0.11.0-rc.0")).To(Equal("v0.11.0-rc.1"))
+		})
+
+		When("the constraint is invalid", func() {
+			It("returns an error", func() {
+				_, err := catalog.ResolveVersion(fakeCatalogClient, "weaveworks", "nginx", ">=foo")
+				Expect(err).To(MatchError(ContainSubstring(`invalid version constraint ">=foo"`)))
+			})
+		})
+	})
+
+	It("tells version constraints from exact versions", func() {
+		for _, constraint := range []string{"~0.3", "^1.2.0", ">=1.0 <2.0", "1.x", "v1.2.*", "!=1.0.0"} {
+			Expect(catalog.IsVersionConstraint(constraint)).To(BeTrue(), constraint)
+		}
+		for _, version := range []string{"", "v0.1.0", "0.1.0", "0.2.0-rc.1", "latest"} {
+			Expect(catalog.IsVersionConstraint(version)).To(BeFalse(), version)
+		}
+	})
+})
@@ -5,13 +5,14 @@ import (
 	"sync"
 
 	"github.com/weaveworks/pctl/pkg/cluster"
+	"sigs.k8s.io/cli-utils/pkg/object"
 )
 
 type FakeWaiter struct {
-	WaitStub        func(...string) error
+	WaitStub        func(...object.ObjMetadata) error
 	waitMutex       sync.RWMutex
 	waitArgsForCall []struct {
-		arg1 []string
+		arg1 []object.ObjMetadata
 	}
 	waitReturns struct {
 		result1 error
@@ -19,15 +20,26 @@ type FakeWaiter struct {
 	waitReturnsOnCall map[int]struct {
 		result1 error
 	}
+	WaitForDeletionStub        func(...object.ObjMetadata) error
+	waitForDeletionMutex       sync.RWMutex
+	waitForDeletionArgsForCall []struct {
+		arg1 []object.ObjMetadata
+	}
+	waitForDeletionReturns struct {
+		result1 error
+	}
+	waitForDeletionReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeWaiter) Wait(arg1 ...string) error {
+func (fake *FakeWaiter) Wait(arg1 ...object.ObjMetadata) error {
 	fake.waitMutex.Lock()
 	ret, specificReturn := fake.waitReturnsOnCall[len(fake.waitArgsForCall)]
 	fake.waitArgsForCall = append(fake.waitArgsForCall, struct {
-		arg1 []string
+		arg1 []object.ObjMetadata
 	}{arg1})
 	stub := fake.WaitStub
 	fakeReturns := fake.waitReturns
@@ -48,13 +60,13 @@ func (fake *FakeWaiter) WaitCallCount() int {
 	return len(fake.waitArgsForCall)
 }
 
-func (fake *FakeWaiter) WaitCalls(stub func(...string) error) {
+func (fake *FakeWaiter) WaitCalls(stub func(...object.ObjMetadata) error) {
 	fake.waitMutex.Lock()
 	defer fake.waitMutex.Unlock()
 	fake.WaitStub = stub
 }
 
-func (fake *FakeWaiter) WaitArgsForCall(i int) []string {
+func (fake *FakeWaiter) WaitArgsForCall(i int) []object.ObjMetadata {
 	fake.waitMutex.RLock()
 	defer fake.waitMutex.RUnlock()
 	argsForCall := fake.waitArgsForCall[i]
@@ -84,11 +96,74 @@ func (fake *FakeWaiter) WaitReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeWaiter) WaitForDeletion(arg1 ...object.ObjMetadata) error {
+	fake.waitForDeletionMutex.Lock()
+	ret, specificReturn := fake.waitForDeletionReturnsOnCall[len(fake.waitForDeletionArgsForCall)]
+	fake.waitForDeletionArgsForCall = append(fake.waitForDeletionArgsForCall, struct {
+		arg1 []object.ObjMetadata
+	}{arg1})
+	stub := fake.WaitForDeletionStub
+	fakeReturns := fake.waitForDeletionReturns
+	fake.recordInvocation("WaitForDeletion", []interface{}{arg1})
+	fake.waitForDeletionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1...)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeWaiter) WaitForDeletionCallCount() int {
+	fake.waitForDeletionMutex.RLock()
+	defer fake.waitForDeletionMutex.RUnlock()
+	return len(fake.waitForDeletionArgsForCall)
+}
+
+func (fake *FakeWaiter) WaitForDeletionCalls(stub func(...object.ObjMetadata) error) {
+	fake.waitForDeletionMutex.Lock()
+	defer fake.waitForDeletionMutex.Unlock()
+	fake.WaitForDeletionStub = stub
+}
+
+func (fake *FakeWaiter) WaitForDeletionArgsForCall(i int) []object.ObjMetadata {
+	fake.waitForDeletionMutex.RLock()
+	defer fake.waitForDeletionMutex.RUnlock()
+	argsForCall := fake.waitForDeletionArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeWaiter) WaitForDeletionReturns(result1 error) {
+	fake.waitForDeletionMutex.Lock()
+	defer fake.waitForDeletionMutex.Unlock()
+	fake.WaitForDeletionStub = nil
+	fake.waitForDeletionReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeWaiter) WaitForDeletionReturnsOnCall(i int, result1 error) {
+	fake.waitForDeletionMutex.Lock()
+	defer fake.waitForDeletionMutex.Unlock()
+	fake.WaitForDeletionStub = nil
+	if fake.waitForDeletionReturnsOnCall == nil {
+		fake.waitForDeletionReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.waitForDeletionReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeWaiter) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.waitMutex.RLock()
 	defer fake.waitMutex.RUnlock()
+	fake.waitForDeletionMutex.RLock()
+	defer fake.waitForDeletionMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,80 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultFluxVersion is the flux release installed with --install-flux. It serves the API versions pctl
+	// generates resources for.
+	DefaultFluxVersion = "v0.14.2"
+	// DefaultFluxBaseURL is where flux releases are downloaded from.
+	DefaultFluxBaseURL = "https://github.com/fluxcd/flux2/releases"
+	// fluxManifestFile is the release asset containing all flux components.
+	fluxManifestFile = "install.yaml"
+	// fluxInstallNamespace is the namespace the flux install manifest installs flux into.
+	fluxInstallNamespace = "flux-system"
+)
+
+// FetchFlux downloads the flux install manifest of a release into dir.
+func (f *Fetcher) FetchFlux(ctx context.Context, url, version, dir string) error {
+	content, err := f.download(ctx, fmt.Sprintf("%s/download/%s", url, version), fluxManifestFile)
+	if err != nil {
+		return err
+	}
+	return writeFiles(dir, map[string][]byte{fluxManifestFile: content}, fluxManifestFile)
+}
+
+// fluxInstalled returns whether the flux namespace exists.
+func (p *Preparer) fluxInstalled() (bool, error) {
+	if err := p.K8sClient.Get(context.Background(), client.ObjectKey{Name: p.FluxNamespace}, &corev1.Namespace{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get flux namespace: %w", err)
+	}
+	return true, nil
+}
+
+// installFlux applies the flux install manifest if flux isn't installed yet and waits for its controllers
+// to be ready. It returns whether flux was installed.
+func (p *Preparer) installFlux() (bool, error) {
+	if p.FluxNamespace != fluxInstallNamespace {
+		return false, fmt.Errorf("flux can only be installed into the %s namespace, got %s", fluxInstallNamespace, p.FluxNamespace)
+	}
+	installed, err := p.fluxInstalled()
+	if err != nil {
+		return false, err
+	}
+	if installed {
+		fmt.Println("Flux is already installed.")
+		return false, nil
+	}
+	fmt.Printf("Installing flux %s...\n", p.FluxVersion)
+	if err := p.Fetcher.FetchFlux(context.Background(), p.FluxBaseURL, p.FluxVersion, p.Location); err != nil {
+		return false, fmt.Errorf("failed to fetch flux: %w", err)
+	}
+	if err := p.Applier.ApplyFile(filepath.Join(p.Location, fluxManifestFile), p.DryRun); err != nil {
+		return false, fmt.Errorf("failed to install flux: %w", err)
+	}
+	if p.DryRun || p.Applier.NoWait {
+		return true, nil
+	}
+	fmt.Print("Waiting for flux to be ready...")
+	var refs []object.ObjMetadata
+	for _, name := range FluxControllers {
+		refs = append(refs, DeploymentRef(fluxInstallNamespace, name))
+	}
+	if err := p.Applier.Waiter.Wait(refs...); err != nil {
+		return false, fmt.Errorf("failed to wait for flux to be ready: %w", err)
+	}
+	fmt.Println("\ndone.")
+	return true, nil
+}
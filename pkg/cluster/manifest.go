@@ -0,0 +1,75 @@
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// readManifest decodes the objects of a multi-document yaml file. CRDs are ordered first and namespaces
+// second, so they exist before anything that depends on them is applied.
+func readManifest(filename string) ([]*unstructured.Unstructured, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+	var objects []*unstructured.Unstructured
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode manifest file: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		return applyOrder(objects[i]) < applyOrder(objects[j])
+	})
+	return objects, nil
+}
+
+// applyOrder returns the position of an object's kind in the order in which objects are applied.
+func applyOrder(obj *unstructured.Unstructured) int {
+	switch obj.GetKind() {
+	case "CustomResourceDefinition":
+		return 0
+	case "Namespace":
+		return 1
+	}
+	return 2
+}
+
+// printList prints objects as a yaml List.
+func printList(w io.Writer, objects []*unstructured.Unstructured) error {
+	list := &unstructured.UnstructuredList{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+		},
+	}
+	for _, obj := range objects {
+		list.Items = append(list.Items, *obj)
+	}
+	data, err := list.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to encode objects: %w", err)
+	}
+	out, err := sigsyaml.JSONToYAML(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode objects: %w", err)
+	}
+	_, err = w.Write(out)
+	return err
+}
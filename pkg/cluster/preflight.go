@@ -0,0 +1,343 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Masterminds/semver/v3"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// CheckPass means a preflight check succeeded.
+	CheckPass CheckStatus = "pass"
+	// CheckWarn means a preflight check found something which might prevent profiles from working.
+	CheckWarn CheckStatus = "warn"
+	// CheckFail means a preflight check found something which prevents profiles from working.
+	CheckFail CheckStatus = "fail"
+
+	// OutputJSON prints the preflight report as JSON instead of a table.
+	OutputJSON = "json"
+	// MinKubernetesVersion is the oldest Kubernetes version which supports everything prepare applies.
+	MinKubernetesVersion = "1.16.0"
+)
+
+// FluxControllers are the flux controllers which profiles need to be running.
+var FluxControllers = []string{"source-controller", "kustomize-controller", "helm-controller"}
+
+// fluxAPIVersions are the CRDs and their API versions which pctl generates resources for.
+var fluxAPIVersions = []struct {
+	name    string
+	version string
+}{
+	{name: "helmreleases." + helmv2.GroupVersion.Group, version: helmv2.GroupVersion.Version},
+	{name: "kustomizations." + kustomizev1.GroupVersion.Group, version: kustomizev1.GroupVersion.Version},
+	{name: "buckets." + sourcev1.GroupVersion.Group, version: sourcev1.GroupVersion.Version},
+	{name: "gitrepositories." + sourcev1.GroupVersion.Group, version: sourcev1.GroupVersion.Version},
+	{name: "helmcharts." + sourcev1.GroupVersion.Group, version: sourcev1.GroupVersion.Version},
+	{name: "helmrepositories." + sourcev1.GroupVersion.Group, version: sourcev1.GroupVersion.Version},
+}
+
+// preparePermissions are the permissions needed to apply the kinds of resources in the manifest files.
+var preparePermissions = []schema.GroupResource{
+	{Group: apiextensionsv1.GroupName, Resource: "customresourcedefinitions"},
+	{Group: "", Resource: "namespaces"},
+	{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+	{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"},
+	{Group: "apps", Resource: "deployments"},
+}
+
+// CheckStatus is the outcome of a preflight check.
+type CheckStatus string
+
+// CheckResult is the result of running a single preflight check.
+type CheckResult struct {
+	Name    string      `json:"name"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+// Check is a single preflight check.
+type Check interface {
+	// Name of the check as displayed in the report.
+	Name() string
+	// Run the check. Problems are reported in the result, not as an error.
+	Run(ctx context.Context) CheckResult
+}
+
+// Report contains the results of all preflight checks.
+type Report struct {
+	Results []CheckResult `json:"results"`
+}
+
+// RunChecks runs all checks and collects their results.
+func RunChecks(ctx context.Context, checks []Check) Report {
+	report := Report{}
+	for _, check := range checks {
+		result := check.Run(ctx)
+		result.Name = check.Name()
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// Failed returns the results of the checks which failed.
+func (r Report) Failed() []CheckResult {
+	var failed []CheckResult
+	for _, result := range r.Results {
+		if result.Status == CheckFail {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Print writes the report as a table, or as JSON if output is OutputJSON.
+func (r Report) Print(w io.Writer, output string) error {
+	if output == OutputJSON {
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		return e.Encode(r)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tMESSAGE")
+	for _, result := range r.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Name, result.Status, result.Message)
+	}
+	return tw.Flush()
+}
+
+func pass(format string, args ...interface{}) CheckResult {
+	return CheckResult{Status: CheckPass, Message: fmt.Sprintf(format, args...)}
+}
+
+func warn(format string, args ...interface{}) CheckResult {
+	return CheckResult{Status: CheckWarn, Message: fmt.Sprintf(format, args...)}
+}
+
+func fail(format string, args ...interface{}) CheckResult {
+	return CheckResult{Status: CheckFail, Message: fmt.Sprintf(format, args...)}
+}
+
+// ClusterVersionCheck checks that the cluster is at least MinKubernetesVersion.
+type ClusterVersionCheck struct {
+	Discovery discovery.ServerVersionInterface
+}
+
+// Name of the check.
+func (c *ClusterVersionCheck) Name() string {
+	return "cluster-version"
+}
+
+// Run the check.
+func (c *ClusterVersionCheck) Run(ctx context.Context) CheckResult {
+	if c.Discovery == nil {
+		return warn("unable to determine the cluster version")
+	}
+	info, err := c.Discovery.ServerVersion()
+	if err != nil {
+		return fail("failed to get the cluster version: %s", err)
+	}
+	v, err := semver.NewVersion(info.GitVersion)
+	if err != nil {
+		return warn("unable to parse the cluster version %q: %s", info.GitVersion, err)
+	}
+	// versions of managed clusters usually contain a prerelease part, such as v1.19.6-eks-49a6c0.
+	stable, _ := v.SetPrerelease("")
+	if stable.LessThan(semver.MustParse(MinKubernetesVersion)) {
+		return fail("cluster version %s is older than the minimum supported version v%s", info.GitVersion, MinKubernetesVersion)
+	}
+	return pass("cluster version %s is supported", info.GitVersion)
+}
+
+// FluxNamespaceCheck checks that the flux namespace exists.
+type FluxNamespaceCheck struct {
+	Client    client.Client
+	Namespace string
+}
+
+// Name of the check.
+func (c *FluxNamespaceCheck) Name() string {
+	return "flux-namespace"
+}
+
+// Run the check.
+func (c *FluxNamespaceCheck) Run(ctx context.Context) CheckResult {
+	if err := c.Client.Get(ctx, client.ObjectKey{Name: c.Namespace}, &corev1.Namespace{}); err != nil {
+		return fail("failed to get flux namespace: %s", err)
+	}
+	return pass("namespace %s exists", c.Namespace)
+}
+
+// FluxControllersCheck checks that the flux controllers are deployed and ready.
+type FluxControllersCheck struct {
+	Client    client.Client
+	Namespace string
+}
+
+// Name of the check.
+func (c *FluxControllersCheck) Name() string {
+	return "flux-controllers"
+}
+
+// Run the check.
+func (c *FluxControllersCheck) Run(ctx context.Context) CheckResult {
+	var missing, notReady []string
+	for _, name := range FluxControllers {
+		deployment := &appsv1.Deployment{}
+		if err := c.Client.Get(ctx, client.ObjectKey{Name: name, Namespace: c.Namespace}, deployment); err != nil {
+			if apierrors.IsNotFound(err) {
+				missing = append(missing, name)
+				continue
+			}
+			return fail("failed to get deployment %s: %s", name, err)
+		}
+		if !deploymentAvailable(deployment) {
+			notReady = append(notReady, name)
+		}
+	}
+	switch {
+	case len(missing) > 0:
+		return fail("deployments not found in namespace %s: %s", c.Namespace, strings.Join(missing, ", "))
+	case len(notReady) > 0:
+		return warn("deployments not ready: %s", strings.Join(notReady, ", "))
+	}
+	return pass("%s are ready", strings.Join(FluxControllers, ", "))
+}
+
+// deploymentAvailable returns whether the Available condition of a deployment is true.
+func deploymentAvailable(deployment *appsv1.Deployment) bool {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentAvailable {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// FluxCRDsCheck checks that the flux CRDs are installed and serve the API versions pctl generates resources for.
+type FluxCRDsCheck struct {
+	Client client.Client
+}
+
+// Name of the check.
+func (c *FluxCRDsCheck) Name() string {
+	return "flux-crds"
+}
+
+// Run the check.
+func (c *FluxCRDsCheck) Run(ctx context.Context) CheckResult {
+	var problems []string
+	for _, expected := range fluxAPIVersions {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := c.Client.Get(ctx, client.ObjectKey{Name: expected.name}, crd); err != nil {
+			if apierrors.IsNotFound(err) {
+				problems = append(problems, fmt.Sprintf("crd %s not found", expected.name))
+				continue
+			}
+			return fail("failed to get crd %s: %s", expected.name, err)
+		}
+		var served []string
+		found := false
+		for _, v := range crd.Spec.Versions {
+			if !v.Served {
+				continue
+			}
+			served = append(served, v.Name)
+			found = found || v.Name == expected.version
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("crd %s doesn't serve %s (served: %s)", expected.name, expected.version, strings.Join(served, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return fail("%s", strings.Join(problems, "; "))
+	}
+	return pass("all flux crds serve the expected versions")
+}
+
+// RBACCheck checks that the current user is allowed to create the resources in the manifest files.
+type RBACCheck struct {
+	Client client.Client
+}
+
+// Name of the check.
+func (c *RBACCheck) Name() string {
+	return "rbac"
+}
+
+// Run the check.
+func (c *RBACCheck) Run(ctx context.Context) CheckResult {
+	var denied []string
+	for _, gr := range preparePermissions {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Verb:     "create",
+					Group:    gr.Group,
+					Resource: gr.Resource,
+				},
+			},
+		}
+		if err := c.Client.Create(ctx, review); err != nil {
+			return fail("failed to review access to %s: %s", gr, err)
+		}
+		if !review.Status.Allowed {
+			denied = append(denied, gr.String())
+		}
+	}
+	if len(denied) > 0 {
+		return fail("not allowed to create %s", strings.Join(denied, ", "))
+	}
+	return pass("allowed to create all resources")
+}
+
+// CatalogServiceCheck checks whether the catalog service has ready endpoints. It only warns, because the
+// service is installed by prepare.
+type CatalogServiceCheck struct {
+	Client           client.Client
+	ServiceName      string
+	ServiceNamespace string
+}
+
+// Name of the check.
+func (c *CatalogServiceCheck) Name() string {
+	return "catalog-service"
+}
+
+// Run the check.
+func (c *CatalogServiceCheck) Run(ctx context.Context) CheckResult {
+	endpoints := &corev1.Endpoints{}
+	if err := c.Client.Get(ctx, client.ObjectKey{Name: c.ServiceName, Namespace: c.ServiceNamespace}, endpoints); err != nil {
+		if apierrors.IsNotFound(err) {
+			return warn("service %s/%s not found, it will be installed by prepare", c.ServiceNamespace, c.ServiceName)
+		}
+		return warn("failed to get endpoints of service %s/%s: %s", c.ServiceNamespace, c.ServiceName, err)
+	}
+	var addresses []string
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			addresses = append(addresses, address.IP)
+		}
+	}
+	if len(addresses) == 0 {
+		return warn("service %s/%s has no ready endpoints", c.ServiceNamespace, c.ServiceName)
+	}
+	sort.Strings(addresses)
+	return pass("service %s/%s is reachable at %s", c.ServiceNamespace, c.ServiceName, strings.Join(addresses, ", "))
+}
@@ -0,0 +1,184 @@
+package cluster_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+
+	"github.com/weaveworks/pctl/pkg/cluster"
+)
+
+// serverVersion returns a fixed cluster version.
+type serverVersion struct {
+	version string
+	err     error
+}
+
+func (s *serverVersion) ServerVersion() (*version.Info, error) {
+	return &version.Info{GitVersion: s.version}, s.err
+}
+
+// check is a preflight check with a fixed result.
+type check struct {
+	name   string
+	result cluster.CheckResult
+}
+
+func (c *check) Name() string {
+	return c.name
+}
+
+func (c *check) Run(ctx context.Context) cluster.CheckResult {
+	return c.result
+}
+
+var _ = Describe("preflight", func() {
+	ctx := context.Background()
+
+	Context("cluster version", func() {
+		It("passes for supported versions, including ones of managed clusters", func() {
+			for _, v := range []string{"v1.16.0", "v1.20.2", "v1.19.6-eks-49a6c0"} {
+				result := (&cluster.ClusterVersionCheck{Discovery: &serverVersion{version: v}}).Run(ctx)
+				Expect(result.Status).To(Equal(cluster.CheckPass), v)
+			}
+		})
+
+		It("fails for versions older than the minimum", func() {
+			result := (&cluster.ClusterVersionCheck{Discovery: &serverVersion{version: "v1.15.12"}}).Run(ctx)
+			Expect(result.Status).To(Equal(cluster.CheckFail))
+			Expect(result.Message).To(Equal("cluster version v1.15.12 is older than the minimum supported version v1.16.0"))
+		})
+
+		It("fails if the version can't be fetched", func() {
+			result := (&cluster.ClusterVersionCheck{Discovery: &serverVersion{err: errors.New("nope")}}).Run(ctx)
+			Expect(result.Status).To(Equal(cluster.CheckFail))
+			Expect(result.Message).To(Equal("failed to get the cluster version: nope"))
+		})
+
+		It("warns if there is no way to get the version", func() {
+			result := (&cluster.ClusterVersionCheck{}).Run(ctx)
+			Expect(result.Status).To(Equal(cluster.CheckWarn))
+		})
+	})
+
+	Context("flux controllers", func() {
+		It("passes if all controllers are available", func() {
+			result := (&cluster.FluxControllersCheck{Client: newApplyClient(fluxCluster()...), Namespace: "flux"}).Run(ctx)
+			Expect(result.Status).To(Equal(cluster.CheckPass))
+		})
+
+		It("fails if a controller is missing", func() {
+			result := (&cluster.FluxControllersCheck{Client: newApplyClient(fluxCluster("helm-controller")...), Namespace: "flux"}).Run(ctx)
+			Expect(result.Status).To(Equal(cluster.CheckFail))
+			Expect(result.Message).To(Equal("deployments not found in namespace flux: helm-controller"))
+		})
+
+		It("warns if a controller isn't ready", func() {
+			objects := append(fluxCluster("source-controller"), &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "source-controller", Namespace: "flux"},
+			})
+			result := (&cluster.FluxControllersCheck{Client: newApplyClient(objects...), Namespace: "flux"}).Run(ctx)
+			Expect(result.Status).To(Equal(cluster.CheckWarn))
+			Expect(result.Message).To(Equal("deployments not ready: source-controller"))
+		})
+	})
+
+	Context("flux crds", func() {
+		It("passes if all crds serve the expected versions", func() {
+			result := (&cluster.FluxCRDsCheck{Client: newApplyClient(fluxCluster()...)}).Run(ctx)
+			Expect(result.Status).To(Equal(cluster.CheckPass))
+		})
+
+		It("fails if a crd doesn't serve the expected version", func() {
+			objects := append(fluxCluster("helmreleases.helm.toolkit.fluxcd.io"), &apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "helmreleases.helm.toolkit.fluxcd.io"},
+				Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+					Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+						{Name: "v2beta1", Served: false},
+						{Name: "v2beta2", Served: true},
+					},
+				},
+			})
+			result := (&cluster.FluxCRDsCheck{Client: newApplyClient(objects...)}).Run(ctx)
+			Expect(result.Status).To(Equal(cluster.CheckFail))
+			Expect(result.Message).To(Equal("crd helmreleases.helm.toolkit.fluxcd.io doesn't serve v2beta1 (served: v2beta2)"))
+		})
+
+		It("fails if a crd is missing", func() {
+			result := (&cluster.FluxCRDsCheck{Client: newApplyClient(fluxCluster("buckets.source.toolkit.fluxcd.io")...)}).Run(ctx)
+			Expect(result.Status).To(Equal(cluster.CheckFail))
+			Expect(result.Message).To(Equal("crd buckets.source.toolkit.fluxcd.io not found"))
+		})
+	})
+
+	Context("rbac", func() {
+		It("passes if everything can be created", func() {
+			result := (&cluster.RBACCheck{Client: newApplyClient()}).Run(ctx)
+			Expect(result.Status).To(Equal(cluster.CheckPass))
+		})
+
+		It("fails with the denied resources", func() {
+			k8sClient := newApplyClient()
+			k8sClient.denied = []string{"customresourcedefinitions", "clusterroles"}
+			result := (&cluster.RBACCheck{Client: k8sClient}).Run(ctx)
+			Expect(result.Status).To(Equal(cluster.CheckFail))
+			Expect(result.Message).To(Equal("not allowed to create customresourcedefinitions.apiextensions.k8s.io, clusterroles.rbac.authorization.k8s.io"))
+		})
+	})
+
+	Context("catalog service", func() {
+		It("passes if the service has ready endpoints", func() {
+			k8sClient := newApplyClient(&corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: "profiles-catalog-service", Namespace: "profiles-system"},
+				Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+			})
+			result := (&cluster.CatalogServiceCheck{Client: k8sClient, ServiceName: "profiles-catalog-service", ServiceNamespace: "profiles-system"}).Run(ctx)
+			Expect(result.Status).To(Equal(cluster.CheckPass))
+			Expect(result.Message).To(Equal("service profiles-system/profiles-catalog-service is reachable at 10.0.0.1"))
+		})
+
+		It("warns if the service isn't installed yet", func() {
+			result := (&cluster.CatalogServiceCheck{Client: newApplyClient(), ServiceName: "profiles-catalog-service", ServiceNamespace: "profiles-system"}).Run(ctx)
+			Expect(result.Status).To(Equal(cluster.CheckWarn))
+		})
+	})
+
+	Context("report", func() {
+		var report cluster.Report
+
+		BeforeEach(func() {
+			report = cluster.RunChecks(ctx, []cluster.Check{
+				&check{name: "first", result: cluster.CheckResult{Status: cluster.CheckPass, Message: "fine"}},
+				&check{name: "second", result: cluster.CheckResult{Status: cluster.CheckFail, Message: "broken"}},
+			})
+		})
+
+		It("collects the failed checks", func() {
+			Expect(report.Failed()).To(Equal([]cluster.CheckResult{{Name: "second", Status: cluster.CheckFail, Message: "broken"}}))
+		})
+
+		It("prints a table", func() {
+			out := &bytes.Buffer{}
+			Expect(report.Print(out, "")).To(Succeed())
+			Expect(out.String()).To(Equal("CHECK   STATUS  MESSAGE\nfirst   pass    fine\nsecond  fail    broken\n"))
+		})
+
+		It("prints json", func() {
+			out := &bytes.Buffer{}
+			Expect(report.Print(out, cluster.OutputJSON)).To(Succeed())
+			printed := cluster.Report{}
+			Expect(json.Unmarshal(out.Bytes(), &printed)).To(Succeed())
+			Expect(printed).To(Equal(report))
+		})
+	})
+})
@@ -1,7 +1,6 @@
 package cluster
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -9,44 +8,42 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
-	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
-	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
-	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
-
-	"github.com/weaveworks/pctl/pkg/runner"
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	kubectlCmd = "kubectl"
+	// DefaultWaitTimeout is how long prepare waits for resources by default.
+	DefaultWaitTimeout = 15 * time.Minute
+	// FieldManager is the field manager pctl applies objects with using server-side apply.
+	FieldManager = "pctl"
 	// profiles bundles ready to be installed files under `prepare`. The rest of the resources
 	// are left for manual configuration.
 	prepareManifestFile = "prepare.yaml"
 	namespace           = "profiles-system"
 )
 
-// FluxCRDs are CRDs which prepare is checking if they are present in the cluster or not.
-var FluxCRDs = []string{
-	strings.ToLower(helmv2.HelmReleaseKind),
-	strings.ToLower(kustomizev1.KustomizationKind),
-	strings.ToLower(sourcev1.BucketKind),
-	strings.ToLower(sourcev1.GitRepositoryKind),
-	strings.ToLower(sourcev1.HelmChartKind),
-	strings.ToLower(sourcev1.HelmRepositoryKind),
-}
-
 // Fetcher will download a manifest tar file from a remote repository.
 type Fetcher struct {
 	Client *http.Client
+	Verify VerifyConfig
 }
 
 // Applier applies the previously generated manifest files.
 type Applier struct {
-	Runner runner.Runner
+	Client client.Client
 	Waiter Waiter
+	// NoWait returns right after applying or deleting the resources instead of waiting for them.
+	NoWait bool
 }
 
 // Preparer will prepare an environment.
@@ -54,7 +51,8 @@ type Preparer struct {
 	PrepConfig
 	Applier *Applier
 	Fetcher *Fetcher
-	Runner  runner.Runner
+	// Checks are run by PreFlightCheck. If nil, a default set of checks is used.
+	Checks []Check
 }
 
 // PrepConfig defines configuration options for prepare.
@@ -64,13 +62,34 @@ type PrepConfig struct {
 	BaseURL               string
 	Location              string
 	Version               string
-	KubeContext           string
-	KubeConfig            string
 	FluxNamespace         string
 	IgnorePreflightErrors bool
-	DryRun                bool
-	Keep                  bool
-	K8sClient             client.Client
+	// InstallFlux installs flux from FluxBaseURL with FluxVersion if the flux namespace doesn't exist.
+	InstallFlux bool
+	FluxVersion string
+	FluxBaseURL string
+	// WaitTimeout is how long to wait for resources to be ready or removed. Defaults to DefaultWaitTimeout.
+	WaitTimeout time.Duration
+	// NoWait doesn't wait for resources to be ready or removed.
+	NoWait bool
+	// Force allows downgrading an installed profiles controller.
+	Force     bool
+	DryRun    bool
+	Keep      bool
+	K8sClient client.Client
+	// Discovery is used to check the version of the cluster.
+	Discovery discovery.ServerVersionInterface
+	// CatalogServiceName and CatalogServiceNamespace locate the catalog service which is checked for endpoints.
+	CatalogServiceName      string
+	CatalogServiceNamespace string
+	// Output is the format of the preflight report. Either OutputJSON or empty for a table.
+	Output string
+	// Verify defines how the downloaded manifest files are verified.
+	Verify VerifyConfig
+	// FromFile is a local manifest file which is applied as is instead of downloading one.
+	FromFile string
+	// Bundle is a directory created by Download which is verified and applied instead of downloading the manifest files.
+	Bundle string
 }
 
 // NewPreparer creates a preparer with set dependencies ready to be used.
@@ -82,22 +101,34 @@ func NewPreparer(cfg PrepConfig) (*Preparer, error) {
 		}
 		cfg.Location = tmp
 	}
-	r := &runner.CLIRunner{}
+	if cfg.WaitTimeout == 0 {
+		cfg.WaitTimeout = DefaultWaitTimeout
+	}
+	applier := &Applier{
+		Client: cfg.K8sClient,
+		NoWait: cfg.NoWait,
+	}
+	// A client is only needed to wait for resources. It isn't available when only downloading manifest files.
+	if cfg.K8sClient != nil {
+		applier.Waiter = NewKubeWaiter(KubeConfig{
+			Client:   cfg.K8sClient,
+			Interval: 5 * time.Second,
+			Timeout:  cfg.WaitTimeout,
+		})
+	}
+	if cfg.FluxVersion == "" {
+		cfg.FluxVersion = DefaultFluxVersion
+	}
+	if cfg.FluxBaseURL == "" {
+		cfg.FluxBaseURL = DefaultFluxBaseURL
+	}
 	return &Preparer{
 		PrepConfig: cfg,
 		Fetcher: &Fetcher{
 			Client: http.DefaultClient,
+			Verify: cfg.Verify,
 		},
-		Applier: &Applier{
-			Waiter: NewKubeWaiter(KubeConfig{
-				Client:    cfg.K8sClient,
-				Interval:  5 * time.Second,
-				Timeout:   15 * time.Minute,
-				Namespace: namespace,
-			}),
-			Runner: r,
-		},
-		Runner: r,
+		Applier: applier,
 	}, nil
 }
 
@@ -111,74 +142,259 @@ func (p *Preparer) Prepare() error {
 			fmt.Printf("failed to remove temporary folder at location: %s. Please clean manually.", p.Location)
 		}
 	}()
-	if err := p.PreFlightCheck(); err != nil {
+	// in dry-run mode, flux is only printed, so the checks for it would fail.
+	ignorePreflightErrors := p.IgnorePreflightErrors
+	if p.InstallFlux {
+		installed, err := p.installFlux()
+		if err != nil {
+			return err
+		}
+		ignorePreflightErrors = ignorePreflightErrors || (installed && p.DryRun)
+	}
+	if err := p.preFlightCheck(ignorePreflightErrors); err != nil {
+		return err
+	}
+	if err := p.fetchManifest(); err != nil {
 		return err
 	}
-	if err := p.Fetcher.Fetch(context.Background(), p.BaseURL, p.Version, p.Location); err != nil {
+	apply, err := p.checkVersion()
+	if err != nil || !apply {
 		return err
 	}
-	return p.Applier.Apply(p.Location, p.KubeContext, p.KubeConfig, p.DryRun)
+	return p.Applier.Apply(p.Location, p.DryRun)
 }
 
-// PreFlightCheck checks whether prepare can run or not.
-func (p *Preparer) PreFlightCheck() error {
-	fmt.Print("Checking if flux namespace exists...")
-	args := []string{"get", "namespace", p.FluxNamespace, "--output", "name"}
-	if output, err := p.Runner.Run(kubectlCmd, args...); err != nil {
-		fmt.Println("\nOutput from kubectl command: ", string(output))
-		if p.IgnorePreflightErrors {
-			fmt.Println("WARNING: failed to get flux namespace. Flux is required for profiles to work.")
-		} else {
-			return fmt.Errorf("failed to get flux namespace: %w\nTo ignore this error, please see the  --ignore-preflight-checks flag.", err)
-		}
-	}
-	fmt.Println("done.")
-	fmt.Print("Checking for flux CRDs...")
-	output, err := p.Runner.Run(kubectlCmd, "get", "crds", "--output", "jsonpath='{.items[*].spec.names.singular}'")
-	if err != nil {
-		if p.IgnorePreflightErrors {
-			fmt.Println("WARNING: failed to list all installed crds. Flux is required for profiles to work.")
-		} else {
-			return fmt.Errorf("failed to list all installed crds: %w", err)
-		}
-	}
-	// the output contains an opening an closing '
-	output = bytes.Trim(output, "'")
-	// create an easily searchable list of installed CRDs for verification
-	crds := map[string]struct{}{}
-	for _, c := range strings.Split(string(output), " ") {
-		crds[c] = struct{}{}
-	}
-	for _, crd := range FluxCRDs {
-		if _, ok := crds[crd]; !ok {
-			if p.IgnorePreflightErrors {
-				fmt.Println("WARNING: failed to find flux crd resource. Flux is required for profiles to work.")
-			} else {
-				return fmt.Errorf("failed to get crd %s\nTo ignore this error, please see the  --ignore-preflight-checks flag.", crd)
-			}
+// Download fetches and verifies the manifest files without applying them, so they can be used as a bundle
+// on a machine without access to the releases.
+func (p *Preparer) Download() error {
+	if err := p.Fetcher.Download(context.Background(), p.BaseURL, p.Version, p.Location); err != nil {
+		return err
+	}
+	fmt.Printf("Manifest files written to %s.\n", p.Location)
+	return nil
+}
+
+// fetchManifest puts the manifest file into Location. It is read from a local file or bundle if one is
+// configured, otherwise it is downloaded.
+func (p *Preparer) fetchManifest() error {
+	switch {
+	case p.FromFile != "":
+		content, err := ioutil.ReadFile(p.FromFile)
+		if err != nil {
+			return fmt.Errorf("failed to read manifest file: %w", err)
 		}
+		return writeFiles(p.Location, map[string][]byte{prepareManifestFile: content}, prepareManifestFile)
+	case p.Bundle != "":
+		return p.Fetcher.FetchBundle(p.Bundle, p.Location)
+	default:
+		return p.Fetcher.Fetch(context.Background(), p.BaseURL, p.Version, p.Location)
 	}
+}
 
-	fmt.Println("done.")
+// Unprepare will remove everything prepare installed from an environment. Profile subscriptions are removed
+// together with their CRD, so a warning is printed first if there are any left.
+func (p *Preparer) Unprepare() error {
+	defer func() {
+		if p.Keep {
+			return
+		}
+		if err := os.RemoveAll(p.Location); err != nil {
+			fmt.Printf("failed to remove temporary folder at location: %s. Please clean manually.", p.Location)
+		}
+	}()
+	if err := p.warnAboutSubscriptions(); err != nil {
+		return err
+	}
+	if err := p.fetchManifest(); err != nil {
+		return err
+	}
+	return p.Applier.Delete(p.Location, p.DryRun)
+}
+
+// warnAboutSubscriptions prints a warning if there are profile subscriptions in the cluster.
+func (p *Preparer) warnAboutSubscriptions() error {
+	if p.K8sClient == nil {
+		return nil
+	}
+	subs := &profilesv1.ProfileSubscriptionList{}
+	if err := p.K8sClient.List(context.Background(), subs); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to list profile subscriptions: %w", err)
+	}
+	if len(subs.Items) == 0 {
+		return nil
+	}
+	fmt.Printf("WARNING: found %d profile subscription(s), which will be removed together with the profiles controller:\n", len(subs.Items))
+	for _, sub := range subs.Items {
+		fmt.Printf("  %s/%s\n", sub.Namespace, sub.Name)
+	}
 	return nil
 }
 
-// Fetch the latest or a version of the released manifest files for profiles.
+// PreFlightCheck runs the preflight checks and prints their report. It fails if any check failed, unless
+// IgnorePreflightErrors is set.
+func (p *Preparer) PreFlightCheck() error {
+	return p.preFlightCheck(p.IgnorePreflightErrors)
+}
+
+// preFlightCheck runs the preflight checks, only warning about failed checks if ignoreErrors is set.
+func (p *Preparer) preFlightCheck(ignoreErrors bool) error {
+	checks := p.Checks
+	if checks == nil {
+		checks = p.defaultChecks()
+	}
+	report := RunChecks(context.Background(), checks)
+	if err := report.Print(os.Stdout, p.Output); err != nil {
+		return fmt.Errorf("failed to print preflight report: %w", err)
+	}
+	failed := report.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(failed))
+	for _, result := range failed {
+		names = append(names, result.Name)
+	}
+	if ignoreErrors {
+		fmt.Printf("WARNING: preflight checks failed: %s. Flux is required for profiles to work.\n", strings.Join(names, ", "))
+		return nil
+	}
+	return fmt.Errorf("preflight checks failed: %s\nTo ignore this error, please see the --ignore-preflight-errors flag.", strings.Join(names, ", "))
+}
+
+// defaultChecks returns the checks which are run if no checks are configured.
+func (p *Preparer) defaultChecks() []Check {
+	return []Check{
+		&ClusterVersionCheck{Discovery: p.Discovery},
+		&FluxNamespaceCheck{Client: p.K8sClient, Namespace: p.FluxNamespace},
+		&FluxControllersCheck{Client: p.K8sClient, Namespace: p.FluxNamespace},
+		&FluxCRDsCheck{Client: p.K8sClient},
+		&RBACCheck{Client: p.K8sClient},
+		&CatalogServiceCheck{Client: p.K8sClient, ServiceName: p.CatalogServiceName, ServiceNamespace: p.CatalogServiceNamespace},
+	}
+}
+
+// assetGetter returns the content of a release asset by file name.
+type assetGetter func(filename string) ([]byte, error)
+
+// Fetch the latest or a version of the released manifest files for profiles. Unless verification is skipped,
+// the manifest is checked against the checksums file of the release and, if configured, its signature.
 func (f *Fetcher) Fetch(ctx context.Context, url, version, dir string) error {
-	ghURL := fmt.Sprintf("%s/latest/download/%s", url, prepareManifestFile)
+	files, err := f.release(f.remoteAssets(ctx, url, version))
+	if err != nil {
+		return err
+	}
+	return writeFiles(dir, files, prepareManifestFile)
+}
+
+// FetchBundle reads the manifest files from a bundle directory created by Download and verifies them
+// the same way Fetch does.
+func (f *Fetcher) FetchBundle(bundle, dir string) error {
+	files, err := f.release(func(filename string) ([]byte, error) {
+		content, err := ioutil.ReadFile(filepath.Join(bundle, filename))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from bundle %s: %w", filename, bundle, err)
+		}
+		return content, nil
+	})
+	if err != nil {
+		return err
+	}
+	return writeFiles(dir, files, prepareManifestFile)
+}
+
+// Download fetches and verifies the manifest files like Fetch does, and writes them to dir together with
+// the files used to verify them, so dir can be used as a bundle.
+func (f *Fetcher) Download(ctx context.Context, url, version, dir string) error {
+	files, err := f.release(f.remoteAssets(ctx, url, version))
+	if err != nil {
+		return err
+	}
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return writeFiles(dir, files, names...)
+}
+
+// release returns the manifest and the files which were used to verify it.
+func (f *Fetcher) release(get assetGetter) (map[string][]byte, error) {
+	content, err := get(prepareManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{prepareManifestFile: content}
+	if f.Verify.InsecureSkipVerify {
+		fmt.Printf("WARNING: skipping verification of %s.\n", prepareManifestFile)
+		return files, nil
+	}
+	if err := f.verify(get, files); err != nil {
+		return nil, fmt.Errorf("failed to verify %s: %w\nTo skip verification, please see the --insecure-skip-verify flag.", prepareManifestFile, err)
+	}
+	return files, nil
+}
+
+// verify checks the manifest against the checksums file of the release and the signature of the checksums file.
+// The files used for the verification are added to files.
+func (f *Fetcher) verify(get assetGetter, files map[string][]byte) error {
+	checksums, err := get(checksumsFile)
+	if err != nil {
+		return err
+	}
+	files[checksumsFile] = checksums
+	if f.Verify.SignatureType != "" {
+		signatureFile, err := f.Verify.signatureFile()
+		if err != nil {
+			return err
+		}
+		signature, err := get(signatureFile)
+		if err != nil {
+			return err
+		}
+		files[signatureFile] = signature
+		if err := f.Verify.verifySignature(checksums, signature); err != nil {
+			return err
+		}
+	}
+	return verifyChecksum(checksums, prepareManifestFile, files[prepareManifestFile])
+}
+
+// remoteAssets returns an assetGetter which downloads the assets of the latest or a given release.
+func (f *Fetcher) remoteAssets(ctx context.Context, url, version string) assetGetter {
+	releaseURL := fmt.Sprintf("%s/latest/download", url)
 	hasVersionPrefix := strings.HasPrefix(version, "v")
 	if hasVersionPrefix {
-		ghURL = fmt.Sprintf("%s/download/%s/%s", url, version, prepareManifestFile)
+		releaseURL = fmt.Sprintf("%s/download/%s", url, version)
+	}
+	return func(filename string) ([]byte, error) {
+		return f.download(ctx, releaseURL, filename)
 	}
+}
 
-	req, err := http.NewRequest("GET", ghURL, nil)
+// writeFiles writes the named files to dir.
+func writeFiles(dir string, files map[string][]byte, names ...string) error {
+	for _, name := range names {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), files[name], os.ModePerm); err != nil {
+			return fmt.Errorf("failed to write out file to location: %w", err)
+		}
+	}
+	return nil
+}
+
+// download returns the content of a release asset.
+func (f *Fetcher) download(ctx context.Context, releaseURL, filename string) ([]byte, error) {
+	assetURL := fmt.Sprintf("%s/%s", releaseURL, filename)
+	req, err := http.NewRequest("GET", assetURL, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create HTTP request for %s, error: %w", ghURL, err)
+		return nil, fmt.Errorf("failed to create HTTP request for %s, error: %w", assetURL, err)
 	}
 
 	resp, err := f.Client.Do(req.WithContext(ctx))
 	if err != nil {
-		return fmt.Errorf("failed to download prepare.yaml from %s, error: %w", ghURL, err)
+		return nil, fmt.Errorf("failed to download %s from %s, error: %w", filename, assetURL, err)
 	}
 	defer func(body io.ReadCloser) {
 		if err := body.Close(); err != nil {
@@ -187,46 +403,105 @@ func (f *Fetcher) Fetch(ctx context.Context, url, version, dir string) error {
 	}(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download prepare.yaml from %s, status: %s", ghURL, resp.Status)
+		return nil, fmt.Errorf("failed to download %s from %s, status: %s", filename, assetURL, resp.Status)
 	}
 
 	content, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read body of the response: %w", err)
+		return nil, fmt.Errorf("failed to read body of the response: %w", err)
 	}
+	return content, nil
+}
 
-	if err := ioutil.WriteFile(filepath.Join(dir, prepareManifestFile), content, os.ModePerm); err != nil {
-		return fmt.Errorf("failed to write out file to location: %w", err)
+// Apply applies the fetched manifest files to a cluster using server-side apply. CRDs are applied first.
+// Unless NoWait is set, it waits for the CRDs to be established, the deployments to be ready and the services
+// to have endpoints. With dryRun, the objects are only printed.
+func (a *Applier) Apply(folder string, dryRun bool) error {
+	objects, err := readManifest(filepath.Join(folder, prepareManifestFile))
+	if err != nil {
+		return fmt.Errorf("install failed: %w", err)
 	}
-
+	if err := a.apply(objects, dryRun); err != nil {
+		return fmt.Errorf("install failed: %w", err)
+	}
+	if dryRun || a.NoWait {
+		return nil
+	}
+	fmt.Print("Waiting for resources to be ready...")
+	if err := a.Waiter.Wait(waitRefs(objects)...); err != nil {
+		return fmt.Errorf("failed to wait for resources to be ready: %w", err)
+	}
+	fmt.Println("\ndone.")
 	return nil
 }
 
-// Apply applies the fetched manifest files to a cluster.
-func (a *Applier) Apply(folder string, kubeContext string, kubeConfig string, dryRun bool) error {
-	kubectlArgs := []string{"apply", "-f", filepath.Join(folder, prepareManifestFile)}
+// ApplyFile applies the objects of a manifest file using server-side apply, CRDs first. With dryRun, the objects
+// are only printed.
+func (a *Applier) ApplyFile(filename string, dryRun bool) error {
+	objects, err := readManifest(filename)
+	if err != nil {
+		return err
+	}
+	return a.apply(objects, dryRun)
+}
+
+// apply applies objects using server-side apply. With dryRun, the objects are only printed.
+func (a *Applier) apply(objects []*unstructured.Unstructured, dryRun bool) error {
 	if dryRun {
-		kubectlArgs = append(kubectlArgs, "--dry-run=client", "--output=yaml")
+		return printList(os.Stdout, objects)
 	}
-	if kubeContext != "" {
-		kubectlArgs = append(kubectlArgs, "--context="+kubeContext)
+	for _, obj := range objects {
+		if err := a.Client.Patch(context.Background(), obj, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership); err != nil {
+			return fmt.Errorf("failed to apply %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		fmt.Printf("%s/%s applied\n", strings.ToLower(obj.GetKind()), obj.GetName())
 	}
-	if kubeConfig != "" {
-		kubectlArgs = append(kubectlArgs, "--kubeconfig="+kubeConfig)
+	return nil
+}
+
+// waitRefs returns references to the objects which are waited for to be ready.
+func waitRefs(objects []*unstructured.Unstructured) []object.ObjMetadata {
+	var refs []object.ObjMetadata
+	for _, obj := range objects {
+		switch obj.GetKind() {
+		case "CustomResourceDefinition":
+			refs = append(refs, CRDRef(obj.GetName()))
+		case "Deployment":
+			refs = append(refs, DeploymentRef(obj.GetNamespace(), obj.GetName()))
+		case "Service":
+			refs = append(refs, ServiceRef(obj.GetNamespace(), obj.GetName()))
+		}
 	}
-	output, err := a.Runner.Run(kubectlCmd, kubectlArgs...)
+	return refs
+}
+
+// Delete removes the resources of the fetched manifest files from a cluster. Objects are deleted in the
+// reverse order of applying them, so CRDs are removed last. With dryRun, the objects are only printed.
+func (a *Applier) Delete(folder string, dryRun bool) error {
+	objects, err := readManifest(filepath.Join(folder, prepareManifestFile))
 	if err != nil {
-		fmt.Println("Log from kubectl: ", string(output))
-		return fmt.Errorf("install failed: %w", err)
+		return err
 	}
 	if dryRun {
-		fmt.Print(string(output))
+		return printList(os.Stdout, objects)
+	}
+	for i := len(objects) - 1; i >= 0; i-- {
+		obj := objects[i]
+		if err := a.Client.Delete(context.Background(), obj); err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				continue
+			}
+			return fmt.Errorf("uninstall failed: failed to delete %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		fmt.Printf("%s/%s deleted\n", strings.ToLower(obj.GetKind()), obj.GetName())
+	}
+	if a.NoWait {
 		return nil
 	}
-	fmt.Print("Waiting for resources to be ready...")
-	if err := a.Waiter.Wait("profiles-controller-manager"); err != nil {
-		return fmt.Errorf("failed to wait for resources to be ready: %w", err)
+	fmt.Print("Waiting for resources to be removed...")
+	if err := a.Waiter.WaitForDeletion(DeploymentRef(namespace, controllerName)); err != nil {
+		return fmt.Errorf("failed to wait for resources to be removed: %w", err)
 	}
-	fmt.Println("done.")
+	fmt.Println("\ndone.")
 	return nil
 }
@@ -2,259 +2,346 @@ package cluster_test
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path"
 	"path/filepath"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
 	"github.com/weaveworks/pctl/pkg/cluster"
 	"github.com/weaveworks/pctl/pkg/cluster/fakes"
-	runnerfake "github.com/weaveworks/pctl/pkg/runner/fakes"
 )
 
+// mockTransport serves release assets by file name.
 type mockTransport struct {
-	res *http.Response
+	files map[string][]byte
 }
 
 func (t *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	return t.res, nil
+	content, ok := t.files[path.Base(req.URL.Path)]
+	if !ok {
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Status:     "404 Not Found",
+			Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+		}, nil
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader(content)),
+	}, nil
+}
+
+// releaseFiles returns the release assets for a prepare.yaml, including a matching checksums file.
+func releaseFiles(content []byte) map[string][]byte {
+	sum := sha256.Sum256(content)
+	return map[string][]byte{
+		"prepare.yaml":  content,
+		"checksums.txt": []byte(fmt.Sprintf("%x  prepare.yaml\n", sum)),
+	}
+}
+
+// applyClient records server-side applies and deletes, which the fake client doesn't support.
+type applyClient struct {
+	client.Client
+	applied      []string
+	fieldManager string
+	force        bool
+	deleted      []string
+	patchErr     error
+	deleteErr    error
+	// denied are the resources for which access reviews are denied.
+	denied []string
+}
+
+func (c *applyClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	review, ok := obj.(*authorizationv1.SelfSubjectAccessReview)
+	if !ok {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+	review.Status.Allowed = true
+	for _, resource := range c.denied {
+		if review.Spec.ResourceAttributes.Resource == resource {
+			review.Status.Allowed = false
+		}
+	}
+	return nil
+}
+
+func (c *applyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+	options := &client.PatchOptions{}
+	options.ApplyOptions(opts)
+	c.fieldManager = options.FieldManager
+	c.force = options.Force != nil && *options.Force
+	c.applied = append(c.applied, obj.GetObjectKind().GroupVersionKind().Kind+"/"+obj.GetName())
+	return c.patchErr
+}
+
+func (c *applyClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.deleted = append(c.deleted, obj.GetObjectKind().GroupVersionKind().Kind+"/"+obj.GetName())
+	return c.deleteErr
+}
+
+// fluxCRDs are the names and API versions of the flux CRDs.
+var fluxCRDs = map[string]string{
+	"buckets.source.toolkit.fluxcd.io":           "v1beta1",
+	"gitrepositories.source.toolkit.fluxcd.io":   "v1beta1",
+	"helmcharts.source.toolkit.fluxcd.io":        "v1beta1",
+	"helmreleases.helm.toolkit.fluxcd.io":        "v2beta1",
+	"helmrepositories.source.toolkit.fluxcd.io":  "v1beta1",
+	"kustomizations.kustomize.toolkit.fluxcd.io": "v1beta1",
+}
+
+// fluxCluster returns the objects of a cluster which has flux installed into the flux namespace. Objects with
+// the given names are left out.
+func fluxCluster(without ...string) []client.Object {
+	objects := []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "flux"}},
+	}
+	for _, name := range cluster.FluxControllers {
+		objects = append(objects, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "flux"},
+			Status: appsv1.DeploymentStatus{
+				Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}},
+			},
+		})
+	}
+	for name, version := range fluxCRDs {
+		objects = append(objects, &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: version, Served: true}},
+			},
+		})
+	}
+	var filtered []client.Object
+	for _, obj := range objects {
+		skip := false
+		for _, name := range without {
+			skip = skip || obj.GetName() == name
+		}
+		if !skip {
+			filtered = append(filtered, obj)
+		}
+	}
+	return filtered
+}
+
+func newApplyClient(objects ...client.Object) *applyClient {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+	Expect(profilesv1.AddToScheme(scheme)).To(Succeed())
+	return &applyClient{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+	}
+}
+
+// appliedObjects is the order in which the objects of testdata/prepare.yaml are applied.
+var appliedObjects = []string{
+	"CustomResourceDefinition/profilecatalogsources.weave.works",
+	"CustomResourceDefinition/profiles.weave.works",
+	"CustomResourceDefinition/profilesubscriptions.weave.works",
+	"Namespace/profiles-system",
+	"Role/profiles-leader-election-role",
+	"ClusterRole/profiles-manager-role",
+	"ClusterRole/profiles-metrics-reader",
+	"ClusterRole/profiles-proxy-role",
+	"RoleBinding/profiles-leader-election-rolebinding",
+	"ClusterRoleBinding/profiles-manager-rolebinding",
+	"ClusterRoleBinding/profiles-proxy-rolebinding",
+	"ConfigMap/profiles-manager-config",
+	"Service/profiles-catalog-service",
+	"Service/profiles-controller-manager-metrics-service",
+	"Deployment/profiles-controller-manager",
 }
 
 var _ = Describe("prepare", func() {
 	var (
-		waiter          *fakes.FakeWaiter
-		applyRunner     *runnerfake.FakeRunner
-		preflightRunner *runnerfake.FakeRunner
+		waiter    *fakes.FakeWaiter
+		k8sClient *applyClient
 	)
 
 	BeforeEach(func() {
 		waiter = &fakes.FakeWaiter{}
-		applyRunner = &runnerfake.FakeRunner{}
-		preflightRunner = &runnerfake.FakeRunner{}
+		k8sClient = newApplyClient(fluxCluster()...)
 	})
 
 	When("dry run is set", func() {
 		It("can prepare the environment with everything that profiles needs without actually modifying the cluster", func() {
-			preflightRunner.RunReturnsOnCall(1, []byte("bucket gitrepository helmchart helmrelease helmrepository kustomization"), nil)
 			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
 			Expect(err).NotTo(HaveOccurred())
 			client := &http.Client{
-				Transport: &mockTransport{
-					res: &http.Response{
-						StatusCode: http.StatusOK,
-						Body:       ioutil.NopCloser(bytes.NewReader(content)),
-					},
-				},
+				Transport: &mockTransport{files: releaseFiles(content)},
 			}
 			tmp, err := ioutil.TempDir("", "prepare_01")
 			Expect(err).NotTo(HaveOccurred())
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
-					Location: tmp,
-					DryRun:   true,
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					Location:      tmp,
+					DryRun:        true,
 				},
 				Fetcher: &cluster.Fetcher{
 					Client: client,
 				},
 				Applier: &cluster.Applier{
+					Client: k8sClient,
 					Waiter: waiter,
-					Runner: applyRunner,
 				},
-				Runner: preflightRunner,
 			}
 			err = p.Prepare()
 			Expect(err).NotTo(HaveOccurred())
-			Expect(preflightRunner.RunCallCount()).To(Equal(2))
-
-			Expect(applyRunner.RunCallCount()).To(Equal(1))
-			arg, args := applyRunner.RunArgsForCall(0)
-			Expect(arg).To(Equal("kubectl"))
-			Expect(args).To(Equal([]string{"apply", "-f", filepath.Join(tmp, "prepare.yaml"), "--dry-run=client", "--output=yaml"}))
+			Expect(k8sClient.applied).To(BeEmpty())
 			Expect(waiter.WaitCallCount()).To(Equal(0))
 		})
 	})
 	When("dry-run is not set", func() {
 		It("sets up the environment with everything that profiles needs", func() {
-			preflightRunner.RunReturnsOnCall(1, []byte("bucket gitrepository helmchart helmrelease helmrepository kustomization"), nil)
 			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
 			Expect(err).NotTo(HaveOccurred())
 			client := &http.Client{
-				Transport: &mockTransport{
-					res: &http.Response{
-						StatusCode: http.StatusOK,
-						Body:       ioutil.NopCloser(bytes.NewReader(content)),
-					},
-				},
+				Transport: &mockTransport{files: releaseFiles(content)},
 			}
 			tmp, err := ioutil.TempDir("", "prepare_02")
 			Expect(err).NotTo(HaveOccurred())
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
-					BaseURL:  "https://github.com/weaveworks/profiles/releases",
-					Location: tmp,
-				},
-				Fetcher: &cluster.Fetcher{
-					Client: client,
-				},
-				Applier: &cluster.Applier{
-					Waiter: waiter,
-					Runner: applyRunner,
-				},
-				Runner: preflightRunner,
-			}
-			err = p.Prepare()
-			Expect(err).NotTo(HaveOccurred())
-			Expect(preflightRunner.RunCallCount()).To(Equal(2))
-			Expect(applyRunner.RunCallCount()).To(Equal(1))
-			arg, args := applyRunner.RunArgsForCall(0)
-			Expect(arg).To(Equal("kubectl"))
-			Expect(args).To(Equal([]string{"apply", "-f", filepath.Join(tmp, "prepare.yaml")}))
-			Expect(waiter.WaitCallCount()).To(Equal(1))
-			args = waiter.WaitArgsForCall(0)
-			Expect(args).To(Equal([]string{"profiles-controller-manager"}))
-		})
-	})
-	When("context and config is provided", func() {
-		It("passes that over to kubernetes", func() {
-			preflightRunner.RunReturnsOnCall(1, []byte("bucket gitrepository helmchart helmrelease helmrepository kustomization"), nil)
-			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
-			Expect(err).NotTo(HaveOccurred())
-			client := &http.Client{
-				Transport: &mockTransport{
-					res: &http.Response{
-						StatusCode: http.StatusOK,
-						Body:       ioutil.NopCloser(bytes.NewReader(content)),
-					},
-				},
-			}
-			tmp, err := ioutil.TempDir("", "prepare_02")
-			Expect(err).NotTo(HaveOccurred())
-			p := &cluster.Preparer{
-				PrepConfig: cluster.PrepConfig{
-					BaseURL:     "https://github.com/weaveworks/profiles/releases",
-					Location:    tmp,
-					KubeContext: "context",
-					KubeConfig:  "kubeconfig",
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					BaseURL:       "https://github.com/weaveworks/profiles/releases",
+					Location:      tmp,
 				},
 				Fetcher: &cluster.Fetcher{
 					Client: client,
 				},
 				Applier: &cluster.Applier{
+					Client: k8sClient,
 					Waiter: waiter,
-					Runner: applyRunner,
 				},
-				Runner: preflightRunner,
 			}
 			err = p.Prepare()
 			Expect(err).NotTo(HaveOccurred())
-			Expect(applyRunner.RunCallCount()).To(Equal(1))
-			arg, args := applyRunner.RunArgsForCall(0)
-			Expect(arg).To(Equal("kubectl"))
-			Expect(args).To(Equal([]string{"apply", "-f", filepath.Join(tmp, "prepare.yaml"), "--context=context", "--kubeconfig=kubeconfig"}))
+			Expect(k8sClient.applied).To(Equal(appliedObjects))
+			Expect(k8sClient.fieldManager).To(Equal("pctl"))
+			Expect(k8sClient.force).To(BeTrue())
 			Expect(waiter.WaitCallCount()).To(Equal(1))
-			args = waiter.WaitArgsForCall(0)
-			Expect(args).To(Equal([]string{"profiles-controller-manager"}))
+			Expect(waiter.WaitArgsForCall(0)).To(Equal([]object.ObjMetadata{
+				cluster.CRDRef("profilecatalogsources.weave.works"),
+				cluster.CRDRef("profiles.weave.works"),
+				cluster.CRDRef("profilesubscriptions.weave.works"),
+				cluster.ServiceRef("profiles-system", "profiles-catalog-service"),
+				cluster.ServiceRef("profiles-system", "profiles-controller-manager-metrics-service"),
+				cluster.DeploymentRef("profiles-system", "profiles-controller-manager"),
+			}))
 		})
 	})
-	When("there is an error running kubectl apply", func() {
+	When("there is an error applying the manifest files", func() {
 		It("will fail and show a proper error to the user", func() {
-			applyRunner.RunReturns([]byte("nope"), errors.New("nope"))
-			preflightRunner.RunReturnsOnCall(1, []byte("bucket gitrepository helmchart helmrelease helmrepository kustomization"), nil)
+			k8sClient.patchErr = errors.New("nope")
 			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
 			Expect(err).NotTo(HaveOccurred())
 			client := &http.Client{
-				Transport: &mockTransport{
-					res: &http.Response{
-						StatusCode: http.StatusOK,
-						Body:       ioutil.NopCloser(bytes.NewReader(content)),
-					},
-				},
+				Transport: &mockTransport{files: releaseFiles(content)},
 			}
 			tmp, err := ioutil.TempDir("", "specific_version_01")
 			Expect(err).NotTo(HaveOccurred())
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
-					BaseURL:     "https://github.com/weaveworks/profiles/releases",
-					KubeContext: "context",
-					KubeConfig:  "kubeconfig",
-					Location:    tmp,
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					BaseURL:       "https://github.com/weaveworks/profiles/releases",
+					Location:      tmp,
 				},
 				Fetcher: &cluster.Fetcher{
 					Client: client,
 				},
 				Applier: &cluster.Applier{
-					Runner: applyRunner,
+					Client: k8sClient,
 					Waiter: waiter,
 				},
-				Runner: preflightRunner,
 			}
 			err = p.Prepare()
-			Expect(err).To(MatchError("install failed: nope"))
+			Expect(err).To(MatchError("install failed: failed to apply CustomResourceDefinition profilecatalogsources.weave.works: nope"))
 		})
 	})
 	When("a specific version is defined", func() {
 		It("will respect the version and try to download that", func() {
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				Expect(r.URL.String()).To(Equal("/download/v0.0.1/prepare.yaml"))
+				Expect(r.URL.String()).To(HavePrefix("/download/v0.0.1/"))
 			}))
 			tmp, err := ioutil.TempDir("", "specific_version_01")
 			Expect(err).NotTo(HaveOccurred())
-			preflightRunner.RunReturnsOnCall(1, []byte("bucket gitrepository helmchart helmrelease helmrepository kustomization"), nil)
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
-					BaseURL:     server.URL,
-					Version:     "v0.0.1",
-					KubeContext: "context",
-					KubeConfig:  "kubeconfig",
-					Location:    tmp,
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					BaseURL:       server.URL,
+					Version:       "v0.0.1",
+					Location:      tmp,
 				},
 				Fetcher: &cluster.Fetcher{
 					Client: server.Client(),
 				},
 				Applier: &cluster.Applier{
-					Runner: applyRunner,
+					Client: k8sClient,
 					Waiter: waiter,
 				},
-				Runner: preflightRunner,
 			}
 			// we deliberately ignore the error here. the important part is the called url.
 			_ = p.Prepare()
 		})
 		It("the controller has the right version in the file", func() {
-			preflightRunner.RunReturnsOnCall(1, []byte("bucket gitrepository helmchart helmrelease helmrepository kustomization"), nil)
 			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
 			Expect(err).NotTo(HaveOccurred())
 			client := &http.Client{
-				Transport: &mockTransport{
-					res: &http.Response{
-						StatusCode: http.StatusOK,
-						Body:       ioutil.NopCloser(bytes.NewReader(content)),
-					},
-				},
+				Transport: &mockTransport{files: releaseFiles(content)},
 			}
 			tmp, err := ioutil.TempDir("", "prepare_change_version_01")
 			Expect(err).NotTo(HaveOccurred())
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
-					Location: tmp,
-					DryRun:   true,
-					Keep:     true,
-					Version:  "v0.0.1",
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					Location:      tmp,
+					DryRun:        true,
+					Keep:          true,
+					Version:       "v0.0.1",
 				},
 				Fetcher: &cluster.Fetcher{
 					Client: client,
 				},
 				Applier: &cluster.Applier{
+					Client: k8sClient,
 					Waiter: waiter,
-					Runner: applyRunner,
 				},
-				Runner: preflightRunner,
 			}
 			err = p.Prepare()
 			Expect(err).NotTo(HaveOccurred())
@@ -266,27 +353,25 @@ var _ = Describe("prepare", func() {
 		})
 		It("will only try and fetch versions starting with (v)", func() {
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				Expect(r.URL.String()).To(Equal("/latest/download/prepare.yaml"))
+				Expect(r.URL.String()).To(HavePrefix("/latest/download/"))
 			}))
 			tmp, err := ioutil.TempDir("", "specific_version_01")
 			Expect(err).NotTo(HaveOccurred())
-			preflightRunner.RunReturnsOnCall(1, []byte("bucket gitrepository helmchart helmrelease helmrepository kustomization"), nil)
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
-					BaseURL:     server.URL,
-					Version:     "0.0.1",
-					KubeContext: "context",
-					KubeConfig:  "kubeconfig",
-					Location:    tmp,
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					BaseURL:       server.URL,
+					Version:       "0.0.1",
+					Location:      tmp,
 				},
 				Fetcher: &cluster.Fetcher{
 					Client: server.Client(),
 				},
 				Applier: &cluster.Applier{
-					Runner: applyRunner,
+					Client: k8sClient,
 					Waiter: waiter,
 				},
-				Runner: preflightRunner,
 			}
 			// we deliberately ignore the error here. the important part is the called url.
 			_ = p.Prepare()
@@ -297,17 +382,15 @@ var _ = Describe("prepare", func() {
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				w.WriteHeader(http.StatusBadGateway)
 			}))
-			preflightRunner.RunReturnsOnCall(1, []byte("bucket gitrepository helmchart helmrelease helmrepository kustomization"), nil)
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
-					BaseURL:     server.URL,
-					KubeContext: "context",
-					KubeConfig:  "kubeconfig",
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					BaseURL:       server.URL,
 				},
 				Fetcher: &cluster.Fetcher{
 					Client: server.Client(),
 				},
-				Runner: preflightRunner,
 			}
 			err := p.Prepare()
 			msg := fmt.Sprintf("failed to download prepare.yaml from %s/latest/download/prepare.yaml, status: 502 Bad Gateway", server.URL)
@@ -316,15 +399,15 @@ var _ = Describe("prepare", func() {
 	})
 	When("the base url is invalid", func() {
 		It("will provide a sensible failure", func() {
-			preflightRunner.RunReturnsOnCall(1, []byte("bucket gitrepository helmchart helmrelease helmrepository kustomization"), nil)
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
-					BaseURL: "invalid",
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					BaseURL:       "invalid",
 				},
 				Fetcher: &cluster.Fetcher{
 					Client: http.DefaultClient,
 				},
-				Runner: preflightRunner,
 			}
 			err := p.Prepare()
 			Expect(err).To(HaveOccurred())
@@ -333,33 +416,28 @@ var _ = Describe("prepare", func() {
 	})
 	When("the user decided to keep the downloaded file(s)", func() {
 		It("will not delete the downloaded file(s)", func() {
-			preflightRunner.RunReturnsOnCall(1, []byte("bucket gitrepository helmchart helmrelease helmrepository kustomization"), nil)
 			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
 			Expect(err).NotTo(HaveOccurred())
 			client := &http.Client{
-				Transport: &mockTransport{
-					res: &http.Response{
-						StatusCode: http.StatusOK,
-						Body:       ioutil.NopCloser(bytes.NewReader(content)),
-					},
-				},
+				Transport: &mockTransport{files: releaseFiles(content)},
 			}
 			tmp, err := ioutil.TempDir("", "prepare_should_keep_things")
 			Expect(err).NotTo(HaveOccurred())
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
-					BaseURL:  "https://github.com/weaveworks/profiles/releases",
-					Location: tmp,
-					Keep:     true,
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					BaseURL:       "https://github.com/weaveworks/profiles/releases",
+					Location:      tmp,
+					Keep:          true,
 				},
 				Fetcher: &cluster.Fetcher{
 					Client: client,
 				},
 				Applier: &cluster.Applier{
+					Client: k8sClient,
 					Waiter: waiter,
-					Runner: applyRunner,
 				},
-				Runner: preflightRunner,
 			}
 			err = p.Prepare()
 			Expect(err).NotTo(HaveOccurred())
@@ -370,32 +448,27 @@ var _ = Describe("prepare", func() {
 	})
 	When("when all is done", func() {
 		It("should remove any temporary folders", func() {
-			preflightRunner.RunReturnsOnCall(1, []byte("bucket gitrepository helmchart helmrelease helmrepository kustomization"), nil)
 			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
 			Expect(err).NotTo(HaveOccurred())
 			client := &http.Client{
-				Transport: &mockTransport{
-					res: &http.Response{
-						StatusCode: http.StatusOK,
-						Body:       ioutil.NopCloser(bytes.NewReader(content)),
-					},
-				},
+				Transport: &mockTransport{files: releaseFiles(content)},
 			}
 			tmp, err := ioutil.TempDir("", "prepare_should_be_deleted_01")
 			Expect(err).NotTo(HaveOccurred())
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
-					BaseURL:  "https://github.com/weaveworks/profiles/releases",
-					Location: tmp,
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					BaseURL:       "https://github.com/weaveworks/profiles/releases",
+					Location:      tmp,
 				},
 				Fetcher: &cluster.Fetcher{
 					Client: client,
 				},
 				Applier: &cluster.Applier{
+					Client: k8sClient,
 					Waiter: waiter,
-					Runner: applyRunner,
 				},
-				Runner: preflightRunner,
 			}
 			err = p.Prepare()
 			Expect(err).NotTo(HaveOccurred())
@@ -406,32 +479,27 @@ var _ = Describe("prepare", func() {
 	When("the waiter fails to wait", func() {
 		It("prepare should fail in a meaningful way", func() {
 			waiter.WaitReturns(errors.New("nope"))
-			preflightRunner.RunReturnsOnCall(1, []byte("bucket gitrepository helmchart helmrelease helmrepository kustomization"), nil)
 			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
 			Expect(err).NotTo(HaveOccurred())
 			client := &http.Client{
-				Transport: &mockTransport{
-					res: &http.Response{
-						StatusCode: http.StatusOK,
-						Body:       ioutil.NopCloser(bytes.NewReader(content)),
-					},
-				},
+				Transport: &mockTransport{files: releaseFiles(content)},
 			}
 			tmp, err := ioutil.TempDir("", "prepare_should_be_deleted_01")
 			Expect(err).NotTo(HaveOccurred())
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
-					BaseURL:  "https://github.com/weaveworks/profiles/releases",
-					Location: tmp,
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					BaseURL:       "https://github.com/weaveworks/profiles/releases",
+					Location:      tmp,
 				},
 				Fetcher: &cluster.Fetcher{
 					Client: client,
 				},
 				Applier: &cluster.Applier{
-					Runner: applyRunner,
+					Client: k8sClient,
 					Waiter: waiter,
 				},
-				Runner: preflightRunner,
 			}
 			err = p.Prepare()
 			Expect(err).To(HaveOccurred())
@@ -440,21 +508,16 @@ var _ = Describe("prepare", func() {
 	})
 	When("prepare is executed", func() {
 		It("runs a preflight check which will determine if prepare can run", func() {
-			preflightRunner.RunReturnsOnCall(1, []byte("bucket gitrepository helmchart helmrelease helmrepository kustomization"), nil)
 			tmp, err := ioutil.TempDir("", "prepare_preflight_check_01")
 			Expect(err).NotTo(HaveOccurred())
 			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
 			Expect(err).NotTo(HaveOccurred())
 			client := &http.Client{
-				Transport: &mockTransport{
-					res: &http.Response{
-						StatusCode: http.StatusOK,
-						Body:       ioutil.NopCloser(bytes.NewReader(content)),
-					},
-				},
+				Transport: &mockTransport{files: releaseFiles(content)},
 			}
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
+					K8sClient:     k8sClient,
 					BaseURL:       "https://github.com/weaveworks/profiles/releases",
 					Location:      tmp,
 					FluxNamespace: "flux",
@@ -463,40 +526,28 @@ var _ = Describe("prepare", func() {
 					Client: client,
 				},
 				Applier: &cluster.Applier{
-					Runner: applyRunner,
+					Client: k8sClient,
 					Waiter: waiter,
 				},
-				Runner: preflightRunner,
 			}
 			err = p.Prepare()
 			Expect(err).NotTo(HaveOccurred())
-			Expect(preflightRunner.RunCallCount()).To(Equal(2))
-			arg, args := preflightRunner.RunArgsForCall(0)
-			Expect(arg).To(Equal("kubectl"))
-			Expect(args).To(Equal([]string{"get", "namespace", "flux", "--output", "name"}))
-
-			arg, args = preflightRunner.RunArgsForCall(1)
-			Expect(arg).To(Equal("kubectl"))
-			Expect(args).To(Equal([]string{"get", "crds", "--output", "jsonpath='{.items[*].spec.names.singular}'"}))
+			Expect(k8sClient.applied).To(Equal(appliedObjects))
 		})
 	})
 	When("the flux namespace is not there", func() {
 		It("will run nothing else until that is resolved", func() {
-			preflightRunner.RunReturns(nil, errors.New("nope"))
+			k8sClient = newApplyClient()
 			tmp, err := ioutil.TempDir("", "prepare_preflight_check_02")
 			Expect(err).NotTo(HaveOccurred())
 			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
 			Expect(err).NotTo(HaveOccurred())
 			client := &http.Client{
-				Transport: &mockTransport{
-					res: &http.Response{
-						StatusCode: http.StatusOK,
-						Body:       ioutil.NopCloser(bytes.NewReader(content)),
-					},
-				},
+				Transport: &mockTransport{files: releaseFiles(content)},
 			}
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
+					K8sClient:     k8sClient,
 					BaseURL:       "https://github.com/weaveworks/profiles/releases",
 					Location:      tmp,
 					FluxNamespace: "flux",
@@ -505,33 +556,28 @@ var _ = Describe("prepare", func() {
 					Client: client,
 				},
 				Applier: &cluster.Applier{
-					Runner: applyRunner,
+					Client: k8sClient,
 					Waiter: waiter,
 				},
-				Runner: preflightRunner,
 			}
 			err = p.Prepare()
-			Expect(err).To(MatchError("failed to get flux namespace: nope\nTo ignore this error, please see the  --ignore-preflight-checks flag."))
-			Expect(applyRunner.RunCallCount()).To(Equal(0))
+			Expect(err).To(MatchError("preflight checks failed: flux-namespace, flux-controllers, flux-crds\nTo ignore this error, please see the --ignore-preflight-errors flag."))
+			Expect(k8sClient.applied).To(BeEmpty())
 		})
 	})
 	When("one of the flux crds is missing", func() {
 		It("will run nothing else until that is resolved", func() {
-			preflightRunner.RunReturnsOnCall(2, nil, errors.New("nope"))
+			k8sClient = newApplyClient(fluxCluster("helmreleases.helm.toolkit.fluxcd.io")...)
 			tmp, err := ioutil.TempDir("", "prepare_preflight_check_02")
 			Expect(err).NotTo(HaveOccurred())
 			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
 			Expect(err).NotTo(HaveOccurred())
 			client := &http.Client{
-				Transport: &mockTransport{
-					res: &http.Response{
-						StatusCode: http.StatusOK,
-						Body:       ioutil.NopCloser(bytes.NewReader(content)),
-					},
-				},
+				Transport: &mockTransport{files: releaseFiles(content)},
 			}
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
+					K8sClient:     k8sClient,
 					BaseURL:       "https://github.com/weaveworks/profiles/releases",
 					Location:      tmp,
 					FluxNamespace: "flux",
@@ -540,33 +586,28 @@ var _ = Describe("prepare", func() {
 					Client: client,
 				},
 				Applier: &cluster.Applier{
-					Runner: applyRunner,
+					Client: k8sClient,
 					Waiter: waiter,
 				},
-				Runner: preflightRunner,
 			}
 			err = p.Prepare()
-			Expect(err).To(MatchError("failed to get crd helmrelease\nTo ignore this error, please see the  --ignore-preflight-checks flag."))
-			Expect(applyRunner.RunCallCount()).To(Equal(0))
+			Expect(err).To(MatchError("preflight checks failed: flux-crds\nTo ignore this error, please see the --ignore-preflight-errors flag."))
+			Expect(k8sClient.applied).To(BeEmpty())
 		})
 	})
 	When("the user decides to ignore preflight-check errors", func() {
 		It("will output them as a warning but will not stop execution", func() {
-			preflightRunner.RunReturns(nil, errors.New("nope"))
+			k8sClient = newApplyClient()
 			tmp, err := ioutil.TempDir("", "prepare_preflight_check_02")
 			Expect(err).NotTo(HaveOccurred())
 			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
 			Expect(err).NotTo(HaveOccurred())
 			client := &http.Client{
-				Transport: &mockTransport{
-					res: &http.Response{
-						StatusCode: http.StatusOK,
-						Body:       ioutil.NopCloser(bytes.NewReader(content)),
-					},
-				},
+				Transport: &mockTransport{files: releaseFiles(content)},
 			}
 			p := &cluster.Preparer{
 				PrepConfig: cluster.PrepConfig{
+					K8sClient:             k8sClient,
 					BaseURL:               "https://github.com/weaveworks/profiles/releases",
 					Location:              tmp,
 					FluxNamespace:         "flux",
@@ -576,14 +617,500 @@ var _ = Describe("prepare", func() {
 					Client: client,
 				},
 				Applier: &cluster.Applier{
-					Runner: applyRunner,
+					Client: k8sClient,
 					Waiter: waiter,
 				},
-				Runner: preflightRunner,
 			}
 			err = p.Prepare()
 			Expect(err).NotTo(HaveOccurred())
-			Expect(applyRunner.RunCallCount()).To(Equal(1))
+			Expect(k8sClient.applied).To(Equal(appliedObjects))
+		})
+	})
+
+	When("no-wait is set", func() {
+		It("returns right after applying the manifest files", func() {
+			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			tmp, err := ioutil.TempDir("", "prepare_no_wait_01")
+			Expect(err).NotTo(HaveOccurred())
+			p := &cluster.Preparer{
+				PrepConfig: cluster.PrepConfig{
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					Location:      tmp,
+				},
+				Fetcher: &cluster.Fetcher{
+					Client: &http.Client{Transport: &mockTransport{files: releaseFiles(content)}},
+				},
+				Applier: &cluster.Applier{
+					Client: k8sClient,
+					Waiter: waiter,
+					NoWait: true,
+				},
+			}
+			Expect(p.Prepare()).To(Succeed())
+			Expect(k8sClient.applied).To(Equal(appliedObjects))
+			Expect(waiter.WaitCallCount()).To(Equal(0))
+		})
+	})
+	When("custom checks are configured", func() {
+		It("runs them instead of the default checks", func() {
+			p := &cluster.Preparer{
+				PrepConfig: cluster.PrepConfig{
+					K8sClient: newApplyClient(),
+				},
+				Checks: []cluster.Check{
+					&check{name: "custom", result: cluster.CheckResult{Status: cluster.CheckFail, Message: "nope"}},
+				},
+			}
+			err := p.PreFlightCheck()
+			Expect(err).To(MatchError("preflight checks failed: custom\nTo ignore this error, please see the --ignore-preflight-errors flag."))
+		})
+	})
+
+	Context("install flux", func() {
+		var (
+			tmp     string
+			files   map[string][]byte
+			passing []cluster.Check
+		)
+
+		newPreparer := func(dryRun bool) *cluster.Preparer {
+			return &cluster.Preparer{
+				PrepConfig: cluster.PrepConfig{
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux-system",
+					Location:      tmp,
+					DryRun:        dryRun,
+					InstallFlux:   true,
+					FluxVersion:   "v0.14.2",
+					FluxBaseURL:   "https://github.com/fluxcd/flux2/releases",
+				},
+				Fetcher: &cluster.Fetcher{
+					Client: &http.Client{Transport: &mockTransport{files: files}},
+				},
+				Applier: &cluster.Applier{
+					Client: k8sClient,
+					Waiter: waiter,
+				},
+				Checks: passing,
+			}
+		}
+
+		BeforeEach(func() {
+			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			files = releaseFiles(content)
+			flux, err := ioutil.ReadFile(filepath.Join("testdata", "flux-install.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			files["install.yaml"] = flux
+			tmp, err = ioutil.TempDir("", "install_flux_01")
+			Expect(err).NotTo(HaveOccurred())
+			k8sClient = newApplyClient()
+			passing = []cluster.Check{
+				&check{name: "passing", result: cluster.CheckResult{Status: cluster.CheckPass}},
+			}
+		})
+
+		It("installs flux and waits for its controllers before applying the manifest files", func() {
+			Expect(newPreparer(false).Prepare()).To(Succeed())
+			Expect(k8sClient.applied).To(Equal(append([]string{
+				"Namespace/flux-system",
+				"Deployment/source-controller",
+			}, appliedObjects...)))
+			Expect(waiter.WaitCallCount()).To(Equal(2))
+			Expect(waiter.WaitArgsForCall(0)).To(Equal([]object.ObjMetadata{
+				cluster.DeploymentRef("flux-system", "source-controller"),
+				cluster.DeploymentRef("flux-system", "kustomize-controller"),
+				cluster.DeploymentRef("flux-system", "helm-controller"),
+			}))
+		})
+
+		It("skips installing flux if it's already installed", func() {
+			k8sClient = newApplyClient(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "flux-system"}})
+			delete(files, "install.yaml")
+			Expect(newPreparer(false).Prepare()).To(Succeed())
+			Expect(k8sClient.applied).To(Equal(appliedObjects))
+			Expect(waiter.WaitCallCount()).To(Equal(1))
+		})
+
+		It("ignores failed preflight checks for flux in dry-run mode", func() {
+			p := newPreparer(true)
+			p.Checks = []cluster.Check{
+				&check{name: "flux-namespace", result: cluster.CheckResult{Status: cluster.CheckFail}},
+			}
+			Expect(p.Prepare()).To(Succeed())
+			Expect(k8sClient.applied).To(BeEmpty())
+			Expect(waiter.WaitCallCount()).To(Equal(0))
+		})
+
+		It("returns a sensible error if flux can't be downloaded", func() {
+			delete(files, "install.yaml")
+			err := newPreparer(false).Prepare()
+			Expect(err).To(MatchError("failed to fetch flux: failed to download install.yaml from https://github.com/fluxcd/flux2/releases/download/v0.14.2/install.yaml, status: 404 Not Found"))
+		})
+
+		It("returns a sensible error if waiting for flux fails", func() {
+			waiter.WaitReturns(errors.New("nope"))
+			err := newPreparer(false).Prepare()
+			Expect(err).To(MatchError("failed to wait for flux to be ready: nope"))
+			Expect(k8sClient.applied).To(HaveLen(2))
+		})
+
+		It("refuses to install flux into another namespace", func() {
+			p := newPreparer(false)
+			p.FluxNamespace = "flux"
+			Expect(p.Prepare()).To(MatchError("flux can only be installed into the flux-system namespace, got flux"))
+		})
+	})
+
+	Context("an older or newer version is installed", func() {
+		var (
+			tmp    string
+			client *http.Client
+		)
+
+		// installed returns the profiles controller deployment of a previous prepare.
+		installed := func(image string, labels map[string]string) *appsv1.Deployment {
+			return &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "profiles-controller-manager", Namespace: "profiles-system", Labels: labels},
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "manager", Image: image}},
+						},
+					},
+				},
+			}
+		}
+
+		newPreparer := func(force bool) *cluster.Preparer {
+			return &cluster.Preparer{
+				PrepConfig: cluster.PrepConfig{
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					Location:      tmp,
+					Force:         force,
+				},
+				Fetcher: &cluster.Fetcher{
+					Client: client,
+				},
+				Applier: &cluster.Applier{
+					Client: k8sClient,
+					Waiter: waiter,
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			client = &http.Client{
+				Transport: &mockTransport{files: releaseFiles(content)},
+			}
+			tmp, err = ioutil.TempDir("", "prepare_version_01")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("does nothing if the same version is already installed", func() {
+			k8sClient = newApplyClient(append(fluxCluster(), installed("weaveworks/profiles-controller:v0.0.1", nil))...)
+			Expect(newPreparer(false).Prepare()).To(Succeed())
+			Expect(k8sClient.applied).To(BeEmpty())
+			Expect(waiter.WaitCallCount()).To(Equal(0))
+		})
+
+		It("upgrades an older version", func() {
+			k8sClient = newApplyClient(append(fluxCluster(), installed("weaveworks/profiles-controller:v0.0.0", nil))...)
+			Expect(newPreparer(false).Prepare()).To(Succeed())
+			Expect(k8sClient.applied).To(Equal(appliedObjects))
+			Expect(waiter.WaitCallCount()).To(Equal(1))
+		})
+
+		It("refuses to downgrade a newer version", func() {
+			k8sClient = newApplyClient(append(fluxCluster(), installed("docker.io/weaveworks/profiles-controller:v0.1.0", nil))...)
+			err := newPreparer(false).Prepare()
+			Expect(err).To(MatchError("refusing to downgrade profiles controller from v0.1.0 to v0.0.1\nTo downgrade anyway, please see the --force flag."))
+			Expect(k8sClient.applied).To(BeEmpty())
+		})
+
+		It("downgrades a newer version if forced", func() {
+			k8sClient = newApplyClient(append(fluxCluster(), installed("weaveworks/profiles-controller:v0.1.0", nil))...)
+			Expect(newPreparer(true).Prepare()).To(Succeed())
+			Expect(k8sClient.applied).To(Equal(appliedObjects))
+		})
+
+		It("prefers the version label over the image tag", func() {
+			k8sClient = newApplyClient(append(fluxCluster(), installed("weaveworks/profiles-controller:latest", map[string]string{
+				"app.kubernetes.io/version": "v0.0.1",
+			}))...)
+			Expect(newPreparer(false).Prepare()).To(Succeed())
+			Expect(k8sClient.applied).To(BeEmpty())
+		})
+
+		It("returns a sensible error if the installed version can't be parsed", func() {
+			k8sClient = newApplyClient(append(fluxCluster(), installed("weaveworks/profiles-controller:latest", nil))...)
+			err := newPreparer(false).Prepare()
+			Expect(err).To(MatchError(ContainSubstring(`failed to parse version "latest" of profiles-controller-manager`)))
+		})
+	})
+
+	Context("unprepare", func() {
+		var (
+			tmp    string
+			client *http.Client
+		)
+
+		BeforeEach(func() {
+			content, err := ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			client = &http.Client{
+				Transport: &mockTransport{files: releaseFiles(content)},
+			}
+			tmp, err = ioutil.TempDir("", "unprepare_01")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("deletes the resources and waits for the controller to be removed", func() {
+			k8sClient = newApplyClient(&profilesv1.ProfileSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+			})
+			p := &cluster.Preparer{
+				PrepConfig: cluster.PrepConfig{
+					Location:  tmp,
+					K8sClient: k8sClient,
+				},
+				Fetcher: &cluster.Fetcher{
+					Client: client,
+				},
+				Applier: &cluster.Applier{
+					Client: k8sClient,
+					Waiter: waiter,
+				},
+			}
+			err := p.Unprepare()
+			Expect(err).NotTo(HaveOccurred())
+			deleted := make([]string, 0, len(appliedObjects))
+			for i := len(appliedObjects) - 1; i >= 0; i-- {
+				deleted = append(deleted, appliedObjects[i])
+			}
+			Expect(k8sClient.deleted).To(Equal(deleted))
+			Expect(waiter.WaitForDeletionCallCount()).To(Equal(1))
+			Expect(waiter.WaitForDeletionArgsForCall(0)).To(Equal([]object.ObjMetadata{cluster.DeploymentRef("profiles-system", "profiles-controller-manager")}))
+			Expect(waiter.WaitCallCount()).To(Equal(0))
+		})
+
+		It("doesn't wait if dry-run is set", func() {
+			p := &cluster.Preparer{
+				PrepConfig: cluster.PrepConfig{
+					K8sClient: k8sClient,
+					Location:  tmp,
+					DryRun:    true,
+				},
+				Fetcher: &cluster.Fetcher{
+					Client: client,
+				},
+				Applier: &cluster.Applier{
+					Client: k8sClient,
+					Waiter: waiter,
+				},
+			}
+			err := p.Unprepare()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.deleted).To(BeEmpty())
+			Expect(waiter.WaitForDeletionCallCount()).To(Equal(0))
+		})
+
+		It("ignores resources which are already gone", func() {
+			k8sClient.deleteErr = apierrors.NewNotFound(schema.GroupResource{Resource: "deployments"}, "profiles-controller-manager")
+			p := &cluster.Preparer{
+				PrepConfig: cluster.PrepConfig{
+					K8sClient: k8sClient,
+					Location:  tmp,
+				},
+				Fetcher: &cluster.Fetcher{
+					Client: client,
+				},
+				Applier: &cluster.Applier{
+					Client: k8sClient,
+					Waiter: waiter,
+				},
+			}
+			Expect(p.Unprepare()).To(Succeed())
+			Expect(k8sClient.deleted).To(HaveLen(len(appliedObjects)))
+			Expect(waiter.WaitForDeletionCallCount()).To(Equal(1))
+		})
+
+		It("returns a sensible error if deleting a resource fails", func() {
+			k8sClient.deleteErr = errors.New("nope")
+			p := &cluster.Preparer{
+				PrepConfig: cluster.PrepConfig{
+					K8sClient: k8sClient,
+					Location:  tmp,
+				},
+				Fetcher: &cluster.Fetcher{
+					Client: client,
+				},
+				Applier: &cluster.Applier{
+					Client: k8sClient,
+					Waiter: waiter,
+				},
+			}
+			err := p.Unprepare()
+			Expect(err).To(MatchError("uninstall failed: failed to delete Deployment profiles-controller-manager: nope"))
+		})
+
+		It("returns a sensible error if waiting for the removal fails", func() {
+			waiter.WaitForDeletionReturns(errors.New("nope"))
+			p := &cluster.Preparer{
+				PrepConfig: cluster.PrepConfig{
+					K8sClient: k8sClient,
+					Location:  tmp,
+				},
+				Fetcher: &cluster.Fetcher{
+					Client: client,
+				},
+				Applier: &cluster.Applier{
+					Client: k8sClient,
+					Waiter: waiter,
+				},
+			}
+			err := p.Unprepare()
+			Expect(err).To(MatchError("failed to wait for resources to be removed: nope"))
+		})
+	})
+
+	Context("air-gapped", func() {
+		var (
+			content []byte
+			tmp     string
+		)
+
+		BeforeEach(func() {
+			var err error
+			content, err = ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			tmp, err = ioutil.TempDir("", "air_gapped_01")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(tmp)
+		})
+
+		// offlineFetcher fails the test if anything is downloaded.
+		offlineFetcher := func() *cluster.Fetcher {
+			return &cluster.Fetcher{
+				Client: &http.Client{Transport: &mockTransport{}},
+			}
+		}
+
+		It("applies a local manifest file without downloading anything", func() {
+			p := &cluster.Preparer{
+				PrepConfig: cluster.PrepConfig{
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					Location:      filepath.Join(tmp, "location"),
+					FromFile:      filepath.Join("testdata", "prepare.yaml"),
+					Keep:          true,
+				},
+				Fetcher: offlineFetcher(),
+				Applier: &cluster.Applier{
+					Client: k8sClient,
+					Waiter: waiter,
+				},
+			}
+			Expect(os.Mkdir(p.Location, 0755)).To(Succeed())
+			Expect(p.Prepare()).To(Succeed())
+			applied, err := ioutil.ReadFile(filepath.Join(p.Location, "prepare.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(applied).To(Equal(content))
+			Expect(k8sClient.applied).To(Equal(appliedObjects))
+		})
+
+		It("downloads a bundle which can be applied later", func() {
+			bundle := filepath.Join(tmp, "bundle")
+			Expect(os.Mkdir(bundle, 0755)).To(Succeed())
+			p := &cluster.Preparer{
+				PrepConfig: cluster.PrepConfig{
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					BaseURL:       "https://github.com/weaveworks/profiles/releases",
+					Location:      bundle,
+				},
+				Fetcher: &cluster.Fetcher{
+					Client: &http.Client{Transport: &mockTransport{files: releaseFiles(content)}},
+				},
+				Applier: &cluster.Applier{
+					Client: k8sClient,
+					Waiter: waiter,
+				},
+			}
+			Expect(p.Download()).To(Succeed())
+			Expect(filepath.Join(bundle, "prepare.yaml")).To(BeAnExistingFile())
+			Expect(filepath.Join(bundle, "checksums.txt")).To(BeAnExistingFile())
+			Expect(k8sClient.applied).To(BeEmpty())
+
+			location := filepath.Join(tmp, "location")
+			Expect(os.Mkdir(location, 0755)).To(Succeed())
+			p = &cluster.Preparer{
+				PrepConfig: cluster.PrepConfig{
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					Location:      location,
+					Bundle:        bundle,
+				},
+				Fetcher: offlineFetcher(),
+				Applier: &cluster.Applier{
+					Client: k8sClient,
+					Waiter: waiter,
+				},
+			}
+			Expect(p.Prepare()).To(Succeed())
+			Expect(k8sClient.applied).To(Equal(appliedObjects))
+			Expect(location).NotTo(BeADirectory())
+			Expect(filepath.Join(bundle, "prepare.yaml")).To(BeAnExistingFile())
+		})
+
+		It("refuses to apply a bundle which fails verification", func() {
+			bundle := filepath.Join(tmp, "bundle")
+			Expect(os.Mkdir(bundle, 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(bundle, "prepare.yaml"), content, 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(bundle, "checksums.txt"), []byte("abc  prepare.yaml\n"), 0644)).To(Succeed())
+			p := &cluster.Preparer{
+				PrepConfig: cluster.PrepConfig{
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					Location:      filepath.Join(tmp, "location"),
+					Bundle:        bundle,
+				},
+				Fetcher: offlineFetcher(),
+				Applier: &cluster.Applier{
+					Client: k8sClient,
+					Waiter: waiter,
+				},
+			}
+			Expect(os.Mkdir(p.Location, 0755)).To(Succeed())
+			err := p.Prepare()
+			Expect(err).To(MatchError(ContainSubstring("failed to verify prepare.yaml: checksum mismatch for prepare.yaml")))
+			Expect(k8sClient.applied).To(BeEmpty())
+		})
+
+		It("returns a sensible error if the bundle is incomplete", func() {
+			p := &cluster.Preparer{
+				PrepConfig: cluster.PrepConfig{
+					K8sClient:     k8sClient,
+					FluxNamespace: "flux",
+					Location:      filepath.Join(tmp, "location"),
+					Bundle:        filepath.Join(tmp, "missing"),
+				},
+				Fetcher: offlineFetcher(),
+				Applier: &cluster.Applier{
+					Client: k8sClient,
+					Waiter: waiter,
+				},
+			}
+			err := p.Prepare()
+			Expect(err).To(MatchError(ContainSubstring("failed to read prepare.yaml from bundle " + filepath.Join(tmp, "missing"))))
 		})
 	})
 })
@@ -0,0 +1,165 @@
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// checksumsFile is the release asset containing the SHA-256 checksums of all other assets.
+	checksumsFile = "checksums.txt"
+	// SignatureCosign verifies a cosign signature of the checksums file against an ECDSA public key.
+	SignatureCosign = "cosign"
+	// SignatureMinisign verifies a minisign signature of the checksums file against an Ed25519 public key.
+	SignatureMinisign = "minisign"
+)
+
+// VerifyConfig defines how downloaded manifest files are verified.
+type VerifyConfig struct {
+	// InsecureSkipVerify disables all verification.
+	InsecureSkipVerify bool
+	// SignatureType is either SignatureCosign or SignatureMinisign. If empty, only the checksum is verified.
+	SignatureType string
+	// PublicKey is the content of the pinned public key used to verify the signature.
+	PublicKey []byte
+}
+
+// signatureFile returns the name of the release asset containing the signature of the checksums file.
+func (v VerifyConfig) signatureFile() (string, error) {
+	switch v.SignatureType {
+	case SignatureCosign:
+		return checksumsFile + ".sig", nil
+	case SignatureMinisign:
+		return checksumsFile + ".minisig", nil
+	}
+	return "", fmt.Errorf("unsupported signature type %q, must be %s or %s", v.SignatureType, SignatureCosign, SignatureMinisign)
+}
+
+// verifySignature verifies the signature of the checksums file with the configured public key.
+func (v VerifyConfig) verifySignature(checksums, signature []byte) error {
+	switch v.SignatureType {
+	case SignatureCosign:
+		return verifyCosign(v.PublicKey, checksums, signature)
+	case SignatureMinisign:
+		return verifyMinisign(v.PublicKey, checksums, signature)
+	}
+	return fmt.Errorf("unsupported signature type %q, must be %s or %s", v.SignatureType, SignatureCosign, SignatureMinisign)
+}
+
+// verifyChecksum checks that the SHA-256 of content matches the entry for filename in the checksums file.
+// Entries are in the `sha256sum` format of `<hex digest>  <filename>`.
+func verifyChecksum(checksums []byte, filename string, content []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != filename {
+			continue
+		}
+		sum := sha256.Sum256(content)
+		if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, fields[0]) {
+			return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", filename, fields[0], actual)
+		}
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", checksumsFile, err)
+	}
+	return fmt.Errorf("no checksum found for %s in %s", filename, checksumsFile)
+}
+
+// verifyCosign verifies a base64 encoded cosign blob signature, which is an ASN.1 ECDSA signature of the
+// SHA-256 of the content, with a PEM encoded public key.
+func verifyCosign(publicKey, content, signature []byte) error {
+	block, _ := pem.Decode(publicKey)
+	if block == nil {
+		return errors.New("failed to decode cosign public key: no PEM block found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse cosign public key: %w", err)
+	}
+	ecdsaKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.New("failed to parse cosign public key: not an ECDSA key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("failed to decode cosign signature: %w", err)
+	}
+	digest := sha256.Sum256(content)
+	if !ecdsa.VerifyASN1(ecdsaKey, digest[:], sig) {
+		return errors.New("cosign signature verification failed")
+	}
+	return nil
+}
+
+// verifyMinisign verifies a minisign signature, including its trusted comment, with a minisign public key.
+func verifyMinisign(publicKey, content, signature []byte) error {
+	keyData, err := decodeMinisignPublicKey(string(publicKey))
+	if err != nil {
+		return fmt.Errorf("failed to decode minisign public key: %w", err)
+	}
+	if len(keyData) != 42 || string(keyData[:2]) != "Ed" {
+		return errors.New("failed to decode minisign public key: invalid key")
+	}
+	lines := strings.Split(strings.TrimSpace(string(signature)), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return errors.New("failed to decode minisign signature: invalid format")
+	}
+	sigData, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil {
+		return fmt.Errorf("failed to decode minisign signature: %w", err)
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil {
+		return fmt.Errorf("failed to decode minisign signature: %w", err)
+	}
+	if len(sigData) != 74 || len(globalSig) != ed25519.SignatureSize {
+		return errors.New("failed to decode minisign signature: invalid signature")
+	}
+	if !bytes.Equal(keyData[2:10], sigData[2:10]) {
+		return errors.New("minisign signature was created with a different key")
+	}
+	key := ed25519.PublicKey(keyData[10:])
+	message := content
+	switch string(sigData[:2]) {
+	case "Ed":
+	case "ED":
+		sum := blake2b.Sum512(content)
+		message = sum[:]
+	default:
+		return fmt.Errorf("unsupported minisign signature algorithm %q", string(sigData[:2]))
+	}
+	sig := sigData[10:]
+	if !ed25519.Verify(key, message, sig) {
+		return errors.New("minisign signature verification failed")
+	}
+	trustedComment := strings.TrimPrefix(strings.TrimRight(lines[2], "\r"), "trusted comment: ")
+	if !ed25519.Verify(key, append(append([]byte{}, sig...), trustedComment...), globalSig) {
+		return errors.New("minisign trusted comment verification failed")
+	}
+	return nil
+}
+
+// decodeMinisignPublicKey decodes the base64 payload of a minisign public key, skipping the untrusted comment if present.
+func decodeMinisignPublicKey(content string) ([]byte, error) {
+	for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		if strings.HasPrefix(line, "untrusted comment:") {
+			continue
+		}
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(line))
+	}
+	return nil, errors.New("missing key data")
+}
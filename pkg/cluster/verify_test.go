@@ -0,0 +1,182 @@
+package cluster_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/weaveworks/pctl/pkg/cluster"
+)
+
+// cosignSign returns a PEM encoded public key and a cosign blob signature of content.
+func cosignSign(content []byte) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	digest := sha256.Sum256(content)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	Expect(err).NotTo(HaveOccurred())
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	Expect(err).NotTo(HaveOccurred())
+	publicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	return publicKey, []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+}
+
+// minisignSign returns a minisign public key and a prehashed minisign signature of content.
+func minisignSign(content []byte) ([]byte, []byte) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	hash := blake2b.Sum512(content)
+	sig := ed25519.Sign(private, hash[:])
+	trustedComment := "timestamp:1621500000\tfile:checksums.txt"
+	globalSig := ed25519.Sign(private, append(append([]byte{}, sig...), trustedComment...))
+
+	publicKey := fmt.Sprintf("untrusted comment: minisign public key\n%s\n",
+		base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), public...)))
+	signature := fmt.Sprintf("untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(append(append([]byte("ED"), keyID...), sig...)),
+		trustedComment,
+		base64.StdEncoding.EncodeToString(globalSig))
+	return []byte(publicKey), []byte(signature)
+}
+
+var _ = Describe("verify", func() {
+	var (
+		tmp     string
+		content []byte
+		files   map[string][]byte
+		fetcher *cluster.Fetcher
+	)
+
+	BeforeEach(func() {
+		var err error
+		content, err = ioutil.ReadFile(filepath.Join("testdata", "prepare.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		files = releaseFiles(content)
+		fetcher = &cluster.Fetcher{
+			Client: &http.Client{Transport: &mockTransport{files: files}},
+		}
+		tmp, err = ioutil.TempDir("", "verify_01")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(tmp)
+	})
+
+	fetch := func() error {
+		return fetcher.Fetch(context.Background(), "https://github.com/weaveworks/profiles/releases", "v0.0.1", tmp)
+	}
+
+	When("the checksum matches", func() {
+		It("writes the manifest", func() {
+			Expect(fetch()).To(Succeed())
+			written, err := ioutil.ReadFile(filepath.Join(tmp, "prepare.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(written).To(Equal(content))
+		})
+	})
+
+	When("the checksum doesn't match", func() {
+		It("refuses to write the manifest", func() {
+			files["prepare.yaml"] = append(content, []byte("# tampered")...)
+			err := fetch()
+			Expect(err).To(MatchError(ContainSubstring("failed to verify prepare.yaml: checksum mismatch for prepare.yaml")))
+			Expect(filepath.Join(tmp, "prepare.yaml")).NotTo(BeAnExistingFile())
+		})
+		It("writes the manifest if verification is skipped", func() {
+			files["prepare.yaml"] = append(content, []byte("# tampered")...)
+			fetcher.Verify.InsecureSkipVerify = true
+			Expect(fetch()).To(Succeed())
+			Expect(filepath.Join(tmp, "prepare.yaml")).To(BeAnExistingFile())
+		})
+	})
+
+	When("the release has no checksums file", func() {
+		It("returns a sensible error", func() {
+			delete(files, "checksums.txt")
+			err := fetch()
+			Expect(err).To(MatchError(ContainSubstring("failed to download checksums.txt from https://github.com/weaveworks/profiles/releases/download/v0.0.1/checksums.txt, status: 404 Not Found")))
+		})
+	})
+
+	When("the checksums file doesn't list the manifest", func() {
+		It("returns a sensible error", func() {
+			files["checksums.txt"] = []byte("abc  other.yaml\n")
+			err := fetch()
+			Expect(err).To(MatchError(ContainSubstring("no checksum found for prepare.yaml in checksums.txt")))
+		})
+	})
+
+	When("a cosign signature is required", func() {
+		BeforeEach(func() {
+			publicKey, signature := cosignSign(files["checksums.txt"])
+			files["checksums.txt.sig"] = signature
+			fetcher.Verify = cluster.VerifyConfig{
+				SignatureType: cluster.SignatureCosign,
+				PublicKey:     publicKey,
+			}
+		})
+		It("verifies the signature of the checksums file", func() {
+			Expect(fetch()).To(Succeed())
+		})
+		It("fails if the signature was made with a different key", func() {
+			fetcher.Verify.PublicKey, _ = cosignSign(files["checksums.txt"])
+			err := fetch()
+			Expect(err).To(MatchError(ContainSubstring("cosign signature verification failed")))
+		})
+		It("fails if the checksums file was modified", func() {
+			files["checksums.txt"] = append(files["checksums.txt"], []byte("abc  other.yaml\n")...)
+			err := fetch()
+			Expect(err).To(MatchError(ContainSubstring("cosign signature verification failed")))
+		})
+	})
+
+	When("a minisign signature is required", func() {
+		BeforeEach(func() {
+			publicKey, signature := minisignSign(files["checksums.txt"])
+			files["checksums.txt.minisig"] = signature
+			fetcher.Verify = cluster.VerifyConfig{
+				SignatureType: cluster.SignatureMinisign,
+				PublicKey:     publicKey,
+			}
+		})
+		It("verifies the signature of the checksums file", func() {
+			Expect(fetch()).To(Succeed())
+		})
+		It("fails if the signature was made with a different key", func() {
+			publicKey, _ := minisignSign(files["checksums.txt"])
+			fetcher.Verify.PublicKey = publicKey
+			err := fetch()
+			Expect(err).To(MatchError(ContainSubstring("minisign signature verification failed")))
+		})
+		It("fails if the signature is missing", func() {
+			delete(files, "checksums.txt.minisig")
+			err := fetch()
+			Expect(err).To(MatchError(ContainSubstring("failed to download checksums.txt.minisig")))
+		})
+	})
+
+	When("the signature type is unknown", func() {
+		It("returns a sensible error", func() {
+			fetcher.Verify.SignatureType = "gpg"
+			err := fetch()
+			Expect(err).To(MatchError(ContainSubstring(`unsupported signature type "gpg", must be cosign or minisign`)))
+		})
+	})
+})
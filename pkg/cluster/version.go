@@ -0,0 +1,110 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	controllerName  = "profiles-controller-manager"
+	controllerImage = "profiles-controller"
+	// versionLabel is the recommended label containing the version of an application.
+	versionLabel = "app.kubernetes.io/version"
+)
+
+// checkVersion compares the version of the installed profiles controller with the version in the fetched
+// manifest file. It returns whether the manifest file should be applied. Downgrades are refused unless Force is set.
+func (p *Preparer) checkVersion() (bool, error) {
+	installed, err := p.installedVersion()
+	if err != nil {
+		return false, err
+	}
+	if installed == nil {
+		return true, nil
+	}
+	objects, err := readManifest(filepath.Join(p.Location, prepareManifestFile))
+	if err != nil {
+		return false, err
+	}
+	requested, err := manifestVersion(objects)
+	if err != nil {
+		fmt.Printf("WARNING: %s. Applying the manifest files over the installed version v%s.\n", err, installed)
+		return true, nil
+	}
+	switch {
+	case requested.Equal(installed):
+		fmt.Printf("Profiles controller is already at v%s.\n", installed)
+		return false, nil
+	case requested.LessThan(installed):
+		if !p.Force {
+			return false, fmt.Errorf("refusing to downgrade profiles controller from v%s to v%s\nTo downgrade anyway, please see the --force flag.", installed, requested)
+		}
+		fmt.Printf("Downgrading profiles controller from v%s to v%s.\n", installed, requested)
+	default:
+		fmt.Printf("Upgrading profiles controller from v%s to v%s.\n", installed, requested)
+	}
+	return true, nil
+}
+
+// installedVersion returns the version of the profiles controller in the cluster, or nil if it isn't installed.
+func (p *Preparer) installedVersion() (*semver.Version, error) {
+	deployment := &appsv1.Deployment{}
+	if err := p.K8sClient.Get(context.Background(), client.ObjectKey{Name: controllerName, Namespace: namespace}, deployment); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get installed profiles controller: %w", err)
+	}
+	return controllerVersion(deployment)
+}
+
+// manifestVersion returns the version of the profiles controller in the objects of a manifest file.
+func manifestVersion(objects []*unstructured.Unstructured) (*semver.Version, error) {
+	for _, obj := range objects {
+		if obj.GetKind() != "Deployment" || obj.GetName() != controllerName {
+			continue
+		}
+		deployment := &appsv1.Deployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, deployment); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", controllerName, err)
+		}
+		return controllerVersion(deployment)
+	}
+	return nil, fmt.Errorf("failed to find %s in the manifest files", controllerName)
+}
+
+// controllerVersion reads the version of the profiles controller from its version label or annotation and falls back
+// to the tag of its image.
+func controllerVersion(deployment *appsv1.Deployment) (*semver.Version, error) {
+	version := deployment.Labels[versionLabel]
+	if version == "" {
+		version = deployment.Annotations[versionLabel]
+	}
+	if version == "" {
+		for _, c := range deployment.Spec.Template.Spec.Containers {
+			image := c.Image[strings.LastIndex(c.Image, "/")+1:]
+			if i := strings.LastIndex(image, ":"); i != -1 && image[:i] == controllerImage {
+				version = image[i+1:]
+				break
+			}
+		}
+	}
+	if version == "" {
+		return nil, fmt.Errorf("failed to find the version of %s", controllerName)
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse version %q of %s: %w", version, controllerName, err)
+	}
+	return v, nil
+}
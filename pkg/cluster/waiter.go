@@ -6,12 +6,19 @@ import (
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/cli-utils/pkg/apply/poller"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/aggregator"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/clusterreader"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/engine"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/statusreaders"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -20,15 +27,31 @@ import (
 // Waiter waits for a set of resources to be Ready.
 //go:generate counterfeiter -o fakes/fake_waiter.go . Waiter
 type Waiter interface {
-	Wait(components ...string) error
+	Wait(objects ...object.ObjMetadata) error
+	// WaitForDeletion waits for a set of resources to be removed.
+	WaitForDeletion(objects ...object.ObjMetadata) error
+}
+
+// DeploymentRef references a deployment, which is ready once its replicas are updated and available.
+func DeploymentRef(namespace, name string) object.ObjMetadata {
+	return object.ObjMetadata{Namespace: namespace, Name: name, GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"}}
+}
+
+// CRDRef references a CRD, which is ready once it's Established.
+func CRDRef(name string) object.ObjMetadata {
+	return object.ObjMetadata{Name: name, GroupKind: schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}}
+}
+
+// ServiceRef references a service, which is ready once its endpoints have a ready address.
+func ServiceRef(namespace, name string) object.ObjMetadata {
+	return object.ObjMetadata{Namespace: namespace, Name: name, GroupKind: schema.GroupKind{Kind: "Service"}}
 }
 
 // KubeConfig defines configurable properties of the kube waiter.
 type KubeConfig struct {
-	Client    client.Client
-	Interval  time.Duration
-	Timeout   time.Duration
-	Namespace string
+	Client   client.Client
+	Interval time.Duration
+	Timeout  time.Duration
 }
 
 // KubeWaiter is a kubernetes waiter.
@@ -39,57 +62,82 @@ type KubeWaiter struct {
 
 // NewKubeWaiter creates a new KubeWaiter.
 func NewKubeWaiter(cfg KubeConfig) *KubeWaiter {
-	p := polling.NewStatusPoller(cfg.Client, cfg.Client.RESTMapper())
 	return &KubeWaiter{
-		KubeConfig:   cfg,
-		StatusPoller: p,
+		KubeConfig: cfg,
+		StatusPoller: &statusPoller{
+			engine: &engine.PollerEngine{
+				Reader: cfg.Client,
+				Mapper: cfg.Client.RESTMapper(),
+			},
+		},
 	}
 }
 
-// Wait waits for some components to be status Ready.
-func (w *KubeWaiter) Wait(components ...string) error {
-	objects, err := w.buildComponentObjectRefs(components...)
-	if err != nil {
-		return err
-	}
+// Wait waits for some resources to be status Ready.
+func (w *KubeWaiter) Wait(objects ...object.ObjMetadata) error {
+	return w.wait(status.CurrentStatus, objects...)
+}
+
+// WaitForDeletion waits for some resources to be status NotFound.
+func (w *KubeWaiter) WaitForDeletion(objects ...object.ObjMetadata) error {
+	return w.wait(status.NotFoundStatus, objects...)
+}
+
+// wait waits for some resources to reach the desired status. Every change of the status of a resource
+// is printed as it happens.
+func (w *KubeWaiter) wait(desired status.Status, objects ...object.ObjMetadata) error {
 	ctx, cancel := context.WithTimeout(context.Background(), w.Timeout)
 	defer cancel()
 
-	opts := polling.Options{PollInterval: w.Interval, UseCache: true}
+	opts := polling.Options{PollInterval: w.Interval, UseCache: false}
 	eventsChan := w.StatusPoller.Poll(ctx, objects, opts)
 
 	coll := collector.NewResourceStatusCollector(objects)
-	done := coll.ListenWithObserver(eventsChan, desiredStatusNotifierFunc(cancel, status.CurrentStatus))
+	done := coll.ListenWithObserver(eventsChan, collector.ObserverFunc(func(rsc *collector.ResourceStatusCollector, e event.Event) {
+		printProgress(e, desired)
+		desiredStatusNotifierFunc(cancel, desired)(rsc, e)
+	}))
 
 	<-done
 
-	for _, rs := range coll.ResourceStatuses {
-		switch rs.Status {
-		case status.CurrentStatus:
-			fmt.Printf("%s: %s ready", rs.Identifier.Name, strings.ToLower(rs.Identifier.GroupKind.Kind))
-		case status.NotFoundStatus:
-			fmt.Printf("%s: %s not found", rs.Identifier.Name, strings.ToLower(rs.Identifier.GroupKind.Kind))
-		default:
-			fmt.Printf("%s: %s not ready", rs.Identifier.Name, strings.ToLower(rs.Identifier.GroupKind.Kind))
-		}
+	if coll.Error != nil {
+		return fmt.Errorf("failed to wait for resources: %w", coll.Error)
 	}
-
-	if coll.Error != nil || ctx.Err() == context.DeadlineExceeded {
-		return fmt.Errorf("timed out waiting for condition")
+	if ctx.Err() == context.DeadlineExceeded {
+		var pending []string
+		for id, rs := range coll.ResourceStatuses {
+			if rs.Status != desired {
+				pending = append(pending, fmt.Sprintf("%s/%s", strings.ToLower(id.GroupKind.Kind), id.Name))
+			}
+		}
+		return fmt.Errorf("timed out waiting for condition: %s", strings.Join(pending, ", "))
 	}
 	return nil
 }
 
-func (w *KubeWaiter) buildComponentObjectRefs(components ...string) ([]object.ObjMetadata, error) {
-	var objRefs []object.ObjMetadata
-	for _, deployment := range components {
-		objMeta, err := object.CreateObjMetadata(w.Namespace, deployment, schema.GroupKind{Group: "apps", Kind: "Deployment"})
-		if err != nil {
-			return nil, err
-		}
-		objRefs = append(objRefs, objMeta)
+// printProgress prints the status of a resource of an update event.
+func printProgress(e event.Event, desired status.Status) {
+	if e.EventType != event.ResourceUpdateEvent || e.Resource == nil {
+		return
+	}
+	rs := e.Resource
+	var state string
+	switch {
+	case rs.Status == status.CurrentStatus:
+		state = "ready"
+	case rs.Status == status.NotFoundStatus && desired == status.NotFoundStatus:
+		state = "deleted"
+	case rs.Status == status.NotFoundStatus:
+		state = "not found"
+	case desired == status.NotFoundStatus:
+		state = "not deleted"
+	default:
+		state = "not ready"
 	}
-	return objRefs, nil
+	if rs.Message != "" && rs.Status != desired {
+		state = fmt.Sprintf("%s: %s", state, rs.Message)
+	}
+	fmt.Printf("\n%s: %s %s", rs.Identifier.Name, strings.ToLower(rs.Identifier.GroupKind.Kind), state)
 }
 
 // desiredStatusNotifierFunc returns an Observer function for the
@@ -108,3 +156,77 @@ func desiredStatusNotifierFunc(cancelFunc context.CancelFunc,
 		}
 	}
 }
+
+// statusPoller polls resources like polling.StatusPoller, but a service is only Current once its endpoints
+// have a ready address.
+type statusPoller struct {
+	engine *engine.PollerEngine
+}
+
+// Poll polls the status of the resources until ctx is cancelled.
+func (s *statusPoller) Poll(ctx context.Context, identifiers []object.ObjMetadata, options polling.Options) <-chan event.Event {
+	return s.engine.Poll(ctx, identifiers, engine.Options{
+		PollInterval: options.PollInterval,
+		ClusterReaderFactoryFunc: func(r client.Reader, _ meta.RESTMapper, _ []object.ObjMetadata) (engine.ClusterReader, error) {
+			return &clusterreader.DirectClusterReader{Reader: r}, nil
+		},
+		StatusReadersFactoryFunc: createStatusReaders,
+	})
+}
+
+// createStatusReaders creates the status readers of polling.StatusPoller and one for services.
+func createStatusReaders(reader engine.ClusterReader, mapper meta.RESTMapper) (map[schema.GroupKind]engine.StatusReader, engine.StatusReader) {
+	defaultStatusReader := statusreaders.NewGenericStatusReader(reader, mapper)
+	replicaSetStatusReader := statusreaders.NewReplicaSetStatusReader(reader, mapper, defaultStatusReader)
+	return map[schema.GroupKind]engine.StatusReader{
+		{Group: "apps", Kind: "Deployment"}:  statusreaders.NewDeploymentResourceReader(reader, mapper, replicaSetStatusReader),
+		{Group: "apps", Kind: "StatefulSet"}: statusreaders.NewStatefulSetResourceReader(reader, mapper, defaultStatusReader),
+		{Group: "apps", Kind: "ReplicaSet"}:  replicaSetStatusReader,
+		{Kind: "Service"}:                    &serviceStatusReader{reader: reader, generic: defaultStatusReader},
+	}, defaultStatusReader
+}
+
+// serviceStatusReader computes the status of a service from its endpoints.
+type serviceStatusReader struct {
+	reader  engine.ClusterReader
+	generic engine.StatusReader
+}
+
+// ReadStatus reads the status of a service.
+func (s *serviceStatusReader) ReadStatus(ctx context.Context, identifier object.ObjMetadata) *event.ResourceStatus {
+	return s.withEndpoints(ctx, s.generic.ReadStatus(ctx, identifier))
+}
+
+// ReadStatusForObject reads the status of a service.
+func (s *serviceStatusReader) ReadStatusForObject(ctx context.Context, obj *unstructured.Unstructured) *event.ResourceStatus {
+	return s.withEndpoints(ctx, s.generic.ReadStatusForObject(ctx, obj))
+}
+
+// withEndpoints changes the status of a Current service to InProgress until its endpoints have a ready address.
+func (s *serviceStatusReader) withEndpoints(ctx context.Context, rs *event.ResourceStatus) *event.ResourceStatus {
+	if rs.Status != status.CurrentStatus {
+		return rs
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Endpoints"))
+	key := client.ObjectKey{Namespace: rs.Identifier.Namespace, Name: rs.Identifier.Name}
+	if err := s.reader.Get(ctx, key, u); err != nil {
+		rs.Status = status.InProgressStatus
+		rs.Message = "waiting for endpoints"
+		return rs
+	}
+	endpoints := &corev1.Endpoints{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, endpoints); err != nil {
+		rs.Status = status.UnknownStatus
+		rs.Error = err
+		return rs
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return rs
+		}
+	}
+	rs.Status = status.InProgressStatus
+	rs.Message = "no ready endpoints"
+	return rs
+}
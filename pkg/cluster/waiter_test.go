@@ -2,15 +2,24 @@ package cluster
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/clusterreader"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 type fakePoller struct {
@@ -54,13 +63,12 @@ var _ = Describe("waiter", func() {
 		}
 		waiter := KubeWaiter{
 			KubeConfig: KubeConfig{
-				Interval:  1 * time.Second,
-				Timeout:   2 * time.Second,
-				Namespace: "default",
+				Interval: 1 * time.Second,
+				Timeout:  2 * time.Second,
 			},
 			StatusPoller: p,
 		}
-		err := waiter.Wait("component")
+		err := waiter.Wait(DeploymentRef("default", "component"))
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -71,14 +79,143 @@ var _ = Describe("waiter", func() {
 			}
 			waiter := KubeWaiter{
 				KubeConfig: KubeConfig{
-					Interval:  1 * time.Second,
-					Timeout:   2 * time.Second,
-					Namespace: "default",
+					Interval: 1 * time.Second,
+					Timeout:  2 * time.Second,
 				},
 				StatusPoller: p,
 			}
-			err := waiter.Wait("component")
-			Expect(err).To(MatchError("timed out waiting for condition"))
+			err := waiter.Wait(DeploymentRef("default", "component"))
+			Expect(err).To(MatchError("timed out waiting for condition: deployment/component"))
+		})
+	})
+
+	When("polling the resources fails", func() {
+		It("returns the error of the poller", func() {
+			p := &fakePoller{
+				events: []event.Event{
+					{
+						EventType: event.ErrorEvent,
+						Error:     errors.New("connection refused"),
+					},
+				},
+			}
+			waiter := KubeWaiter{
+				KubeConfig: KubeConfig{
+					Interval: 1 * time.Second,
+					Timeout:  2 * time.Second,
+				},
+				StatusPoller: p,
+			}
+			err := waiter.Wait(DeploymentRef("default", "component"))
+			Expect(err).To(MatchError("failed to wait for resources: connection refused"))
+		})
+	})
+
+	When("waiting for resources to be deleted", func() {
+		It("returns once the resources are not found", func() {
+			p := &fakePoller{
+				events: []event.Event{
+					{
+						EventType: event.ResourceUpdateEvent,
+						Resource: &event.ResourceStatus{
+							Identifier: object.ObjMetadata{
+								Name:      "component",
+								Namespace: "default",
+								GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+							},
+							Status: status.NotFoundStatus,
+						},
+					},
+				},
+			}
+			waiter := KubeWaiter{
+				KubeConfig: KubeConfig{
+					Interval: 1 * time.Second,
+					Timeout:  2 * time.Second,
+				},
+				StatusPoller: p,
+			}
+			err := waiter.WaitForDeletion(DeploymentRef("default", "component"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("returns a timeout error if the resources are still there", func() {
+			p := &fakePoller{
+				events: []event.Event{
+					{
+						EventType: event.ResourceUpdateEvent,
+						Resource: &event.ResourceStatus{
+							Identifier: object.ObjMetadata{
+								Name:      "component",
+								Namespace: "default",
+								GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+							},
+							Status: status.CurrentStatus,
+						},
+					},
+				},
+			}
+			waiter := KubeWaiter{
+				KubeConfig: KubeConfig{
+					Interval: 1 * time.Second,
+					Timeout:  2 * time.Second,
+				},
+				StatusPoller: p,
+			}
+			err := waiter.WaitForDeletion(DeploymentRef("default", "component"))
+			Expect(err).To(MatchError("timed out waiting for condition: deployment/component"))
+		})
+	})
+
+	Context("services", func() {
+		var (
+			k8sClient client.Client
+			reader    *serviceStatusReader
+		)
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			k8sClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+			reader = &serviceStatusReader{
+				reader:  &clusterreader.DirectClusterReader{Reader: k8sClient},
+				generic: &currentStatusReader{},
+			}
+		})
+
+		It("are ready once their endpoints have a ready address", func() {
+			Expect(k8sClient.Create(context.Background(), &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "default"},
+				Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+			})).To(Succeed())
+			rs := reader.ReadStatus(context.Background(), ServiceRef("default", "catalog"))
+			Expect(rs.Status).To(Equal(status.CurrentStatus))
+		})
+
+		It("are in progress without ready addresses", func() {
+			Expect(k8sClient.Create(context.Background(), &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "default"},
+				Subsets:    []corev1.EndpointSubset{{NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+			})).To(Succeed())
+			rs := reader.ReadStatus(context.Background(), ServiceRef("default", "catalog"))
+			Expect(rs.Status).To(Equal(status.InProgressStatus))
+			Expect(rs.Message).To(Equal("no ready endpoints"))
+		})
+
+		It("are in progress without endpoints", func() {
+			rs := reader.ReadStatus(context.Background(), ServiceRef("default", "catalog"))
+			Expect(rs.Status).To(Equal(status.InProgressStatus))
+			Expect(rs.Message).To(Equal("waiting for endpoints"))
 		})
 	})
 })
+
+// currentStatusReader reports every resource as Current.
+type currentStatusReader struct{}
+
+func (c *currentStatusReader) ReadStatus(_ context.Context, identifier object.ObjMetadata) *event.ResourceStatus {
+	return &event.ResourceStatus{Identifier: identifier, Status: status.CurrentStatus}
+}
+
+func (c *currentStatusReader) ReadStatusForObject(_ context.Context, obj *unstructured.Unstructured) *event.ResourceStatus {
+	return &event.ResourceStatus{Status: status.CurrentStatus}
+}
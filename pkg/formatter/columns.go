@@ -0,0 +1,77 @@
+package formatter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+type customColumnsFormatter struct {
+	headers []string
+	columns []*jsonpath.JSONPath
+	options TableOptions
+}
+
+// NewCustomColumnsFormatter formats output into a table with the columns of a spec like NAME:.name,VERSION:.version.
+// Each column shows the value of a jsonpath expression for every item of the object, or for the object itself
+// if it isn't a list.
+func NewCustomColumnsFormatter(spec string) (customColumnsFormatter, error) {
+	if spec == "" {
+		return customColumnsFormatter{}, fmt.Errorf("custom columns must be provided, such as custom-columns=NAME:.name")
+	}
+	var f customColumnsFormatter
+	for _, column := range strings.Split(spec, ",") {
+		parts := strings.SplitN(column, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return customColumnsFormatter{}, fmt.Errorf("invalid custom column %q, must be <HEADER>:<jsonpath>", column)
+		}
+		expr := parts[1]
+		if !strings.Contains(expr, "{") {
+			expr = "{" + expr + "}"
+		}
+		parser := jsonpath.New(parts[0]).AllowMissingKeys(true)
+		if err := parser.Parse(expr); err != nil {
+			return customColumnsFormatter{}, fmt.Errorf("failed to parse jsonpath of custom column %q: %w", column, err)
+		}
+		f.headers = append(f.headers, parts[0])
+		f.columns = append(f.columns, parser)
+	}
+	return f, nil
+}
+
+// WithOptions returns the formatter rendering tables with the given options
+func (f customColumnsFormatter) WithOptions(options TableOptions) customColumnsFormatter {
+	f.options = options
+	return f
+}
+
+// Format renders a row for every item of the object, with a cell for every column
+func (f customColumnsFormatter) Format(data func() interface{}) (string, error) {
+	obj, err := jsonObject(data())
+	if err != nil {
+		return "", err
+	}
+	items, ok := obj.([]interface{})
+	if !ok && obj != nil {
+		items = []interface{}{obj}
+	}
+	contents := TableContents{Headers: f.headers}
+	for _, item := range items {
+		var row []string
+		for i, column := range f.columns {
+			buf := &bytes.Buffer{}
+			if err := column.Execute(buf, item); err != nil {
+				return "", fmt.Errorf("failed to execute jsonpath of custom column %s: %w", f.headers[i], err)
+			}
+			value := buf.String()
+			if value == "" {
+				value = "<none>"
+			}
+			row = append(row, value)
+		}
+		contents.Data = append(contents.Data, row)
+	}
+	return NewTableFormatter().WithOptions(f.options).Format(func() interface{} { return contents })
+}
@@ -0,0 +1,53 @@
+package formatter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/pctl/pkg/formatter"
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+)
+
+var _ = Describe("CustomColumnsFormatter", func() {
+	dataFunc := func() interface{} {
+		return formatter.Printable{
+			Object: []profilesv1.ProfileDescription{
+				{Name: "foo", CatalogSource: "bar", Version: "0.1.0"},
+				{Name: "baz", CatalogSource: "bar"},
+			},
+		}
+	}
+
+	It("formats a row per item with the given columns", func() {
+		f, err := formatter.NewCustomColumnsFormatter("NAME:.name,VERSION:{.version}")
+		Expect(err).NotTo(HaveOccurred())
+		out, err := f.Format(dataFunc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("NAME\tVERSION \nfoo \t0.1.0  \t\nbaz \t<none> \t\n"))
+	})
+
+	It("formats a single object as a row", func() {
+		f, err := formatter.NewCustomColumnsFormatter("NAME:.name")
+		Expect(err).NotTo(HaveOccurred())
+		out, err := f.WithOptions(formatter.TableOptions{NoHeaders: true}).Format(func() interface{} {
+			return profilesv1.ProfileDescription{Name: "foo"}
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("foo\t\n"))
+	})
+
+	It("sorts rows by a column", func() {
+		f, err := formatter.NewCustomColumnsFormatter("NAME:.name")
+		Expect(err).NotTo(HaveOccurred())
+		out, err := f.WithOptions(formatter.TableOptions{NoHeaders: true, SortBy: "name"}).Format(dataFunc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("baz\t\nfoo\t\n"))
+	})
+
+	When("a column is invalid", func() {
+		It("returns an error", func() {
+			_, err := formatter.NewCustomColumnsFormatter("NAME:.name,VERSION")
+			Expect(err).To(MatchError(`invalid custom column "VERSION", must be <HEADER>:<jsonpath>`))
+		})
+	})
+})
@@ -5,3 +5,16 @@ type Formatter interface {
 	// Format will call the getter func and render the returned data
 	Format(getter func() interface{}) (string, error)
 }
+
+// Printable holds the data a command prints in each of the output formats. Formatters accept it from the
+// getter func in place of the data they format.
+type Printable struct {
+	// Table is rendered by the table formatter
+	Table TableContents
+	// Wide is rendered by the wide formatter. Table is rendered if it's empty.
+	Wide TableContents
+	// Names are printed by the name formatter, one per line
+	Names []string
+	// Object is rendered by the json, yaml, jsonpath and go-template formatters
+	Object interface{}
+}
@@ -13,10 +13,18 @@ func NewJSONFormatter() jsonFormatter {
 
 // Format returns the Marshalled json output
 func (f jsonFormatter) Format(data func() interface{}) (string, error) {
-	out, err := json.MarshalIndent(data(), "", "  ")
+	out, err := json.MarshalIndent(object(data()), "", "  ")
 	if err != nil {
 		return "", err
 	}
 
 	return string(out), nil
 }
+
+// object returns the object of a Printable, or the data itself for anything else.
+func object(data interface{}) interface{} {
+	if p, ok := data.(Printable); ok {
+		return p.Object
+	}
+	return data
+}
@@ -0,0 +1,24 @@
+package formatter
+
+import (
+	"errors"
+	"strings"
+)
+
+type nameFormatter struct{}
+
+// NewNameFormatter formats output into a name per line
+func NewNameFormatter() nameFormatter {
+	return nameFormatter{}
+}
+
+// Format returns the names, one per line
+func (f nameFormatter) Format(data func() interface{}) (string, error) {
+	switch d := data().(type) {
+	case Printable:
+		return strings.Join(d.Names, "\n"), nil
+	case []string:
+		return strings.Join(d, "\n"), nil
+	}
+	return "", errors.New("func returned wrong type for name formatter. wanted formatter.Printable")
+}
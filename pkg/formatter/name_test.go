@@ -0,0 +1,26 @@
+package formatter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/pctl/pkg/formatter"
+)
+
+var _ = Describe("NameFormatter", func() {
+	It("formats output as a name per line", func() {
+		dataFunc := func() interface{} {
+			return formatter.Printable{Names: []string{"bar/foo", "bar/baz"}}
+		}
+		out, err := formatter.NewNameFormatter().Format(dataFunc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("bar/foo\nbar/baz"))
+	})
+
+	When("the wrong type is returned in the getter func", func() {
+		It("returns an error", func() {
+			_, err := formatter.NewNameFormatter().Format(func() interface{} { return 1 })
+			Expect(err).To(MatchError("func returned wrong type for name formatter. wanted formatter.Printable"))
+		})
+	})
+})
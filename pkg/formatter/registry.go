@@ -0,0 +1,72 @@
+package formatter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// formatters creates the formatter of each output format. The argument is what's given after the "=" of
+// formats like jsonpath=<template>, the options apply to the formats which render tables.
+var formatters = map[string]func(arg string, options TableOptions) (Formatter, error){
+	"table": noArg("table", func(options TableOptions) Formatter {
+		return NewTableFormatter().WithOptions(options)
+	}),
+	"wide": noArg("wide", func(options TableOptions) Formatter {
+		return NewWideFormatter().WithOptions(options)
+	}),
+	"json": noArg("json", func(TableOptions) Formatter { return NewJSONFormatter() }),
+	"yaml": noArg("yaml", func(TableOptions) Formatter { return NewYAMLFormatter() }),
+	"name": noArg("name", func(TableOptions) Formatter { return NewNameFormatter() }),
+	"jsonpath": func(arg string, _ TableOptions) (Formatter, error) {
+		return NewJSONPathFormatter(arg)
+	},
+	"go-template": func(arg string, _ TableOptions) (Formatter, error) {
+		return NewTemplateFormatter(arg)
+	},
+	"custom-columns": func(arg string, options TableOptions) (Formatter, error) {
+		f, err := NewCustomColumnsFormatter(arg)
+		if err != nil {
+			return nil, err
+		}
+		return f.WithOptions(options), nil
+	},
+}
+
+// New returns the formatter for an output format: table, wide, json, yaml, name, jsonpath=<template>,
+// go-template=<template> or custom-columns=<HEADER>:<jsonpath>,...
+func New(output string, options TableOptions) (Formatter, error) {
+	name, arg := output, ""
+	if i := strings.Index(output, "="); i >= 0 {
+		name, arg = output[:i], output[i+1:]
+	}
+	newFormatter, ok := formatters[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported output format %q, must be one of: %s", output, strings.Join(Outputs(), ", "))
+	}
+	return newFormatter(arg, options)
+}
+
+// Outputs returns the names of the supported output formats
+func Outputs() []string {
+	var names []string
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsTable returns whether an output format renders a table, which is meant to be read rather than parsed.
+func IsTable(output string) bool {
+	return output == "table" || output == "wide"
+}
+
+func noArg(name string, newFormatter func(TableOptions) Formatter) func(string, TableOptions) (Formatter, error) {
+	return func(arg string, options TableOptions) (Formatter, error) {
+		if arg != "" {
+			return nil, fmt.Errorf("output format %s doesn't take an argument", name)
+		}
+		return newFormatter(options), nil
+	}
+}
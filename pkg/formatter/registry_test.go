@@ -0,0 +1,72 @@
+package formatter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/pctl/pkg/formatter"
+)
+
+var _ = Describe("Registry", func() {
+	printable := func() interface{} {
+		return formatter.Printable{
+			Table: formatter.TableContents{
+				Headers: []string{"name"},
+				Data:    [][]string{{"foo"}},
+			},
+			Wide: formatter.TableContents{
+				Headers: []string{"name", "url"},
+				Data:    [][]string{{"foo", "https://example.com"}},
+			},
+			Names:  []string{"catalog/foo"},
+			Object: map[string]string{"name": "foo"},
+		}
+	}
+
+	DescribeTable("formats output in the given format",
+		func(output, expected string) {
+			f, err := formatter.New(output, formatter.TableOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			out, err := f.Format(printable)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(expected))
+		},
+		Entry("table", "table", "NAME \nfoo \t\n"),
+		Entry("wide", "wide", "NAME\tURL                 \nfoo \thttps://example.com\t\n"),
+		Entry("json", "json", "{\n  \"name\": \"foo\"\n}"),
+		Entry("yaml", "yaml", "name: foo"),
+		Entry("name", "name", "catalog/foo"),
+		Entry("jsonpath", "jsonpath={.name}", "foo"),
+		Entry("go-template", "go-template={{.name}}", "foo"),
+		Entry("custom-columns", "custom-columns=NAME:.name", "NAME \nfoo \t\n"),
+	)
+
+	It("passes table options to the formats rendering tables", func() {
+		f, err := formatter.New("wide", formatter.TableOptions{NoHeaders: true})
+		Expect(err).NotTo(HaveOccurred())
+		out, err := f.Format(printable)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("foo\thttps://example.com\t\n"))
+	})
+
+	When("the output format isn't supported", func() {
+		It("returns an error", func() {
+			_, err := formatter.New("xml", formatter.TableOptions{})
+			Expect(err).To(MatchError(`unsupported output format "xml", must be one of: custom-columns, go-template, json, jsonpath, name, table, wide, yaml`))
+		})
+	})
+
+	When("an argument is given to a format which doesn't take one", func() {
+		It("returns an error", func() {
+			_, err := formatter.New("json=foo", formatter.TableOptions{})
+			Expect(err).To(MatchError("output format json doesn't take an argument"))
+		})
+	})
+
+	It("returns whether an output format is a table", func() {
+		Expect(formatter.IsTable("table")).To(BeTrue())
+		Expect(formatter.IsTable("wide")).To(BeTrue())
+		Expect(formatter.IsTable("json")).To(BeFalse())
+	})
+})
@@ -3,13 +3,28 @@ package formatter
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 )
 
 type tableFormatter struct {
-	table  *tablewriter.Table
-	buffer *bytes.Buffer
+	table   *tablewriter.Table
+	buffer  *bytes.Buffer
+	wide    bool
+	options TableOptions
+}
+
+// TableOptions change how a table is rendered
+type TableOptions struct {
+	// NoHeaders leaves out the header row
+	NoHeaders bool
+	// SortBy is the header of the column to sort rows by, ignoring case
+	SortBy string
 }
 
 // TableContents represents the contents of a table
@@ -27,20 +42,143 @@ func NewTableFormatter() tableFormatter {
 	}
 }
 
+// NewWideFormatter returns a table formatter which renders the wide table of a Printable
+func NewWideFormatter() tableFormatter {
+	f := NewTableFormatter()
+	f.wide = true
+	return f
+}
+
+// WithOptions returns the formatter rendering tables with the given options
+func (f tableFormatter) WithOptions(options TableOptions) tableFormatter {
+	f.options = options
+	return f
+}
+
 // Format receives column names and table data and creates a table in the writer
 func (f tableFormatter) Format(contentFunc func() interface{}) (string, error) {
-	contents, ok := contentFunc().(TableContents)
-	if !ok {
+	var contents TableContents
+	switch data := contentFunc().(type) {
+	case TableContents:
+		contents = data
+	case Printable:
+		contents = data.Table
+		if f.wide && (len(data.Wide.Headers) > 0 || len(data.Wide.Data) > 0) {
+			contents = data.Wide
+		}
+	default:
 		return "", errors.New("func returned wrong type for table formatter. wanted formatter.TableContents")
 	}
 
-	f.table.SetHeader(contents.Headers)
-	f.table.AppendBulk(contents.Data)
+	data := contents.Data
+	if f.options.SortBy != "" {
+		var err error
+		if data, err = sortRows(contents, f.options.SortBy); err != nil {
+			return "", err
+		}
+	}
+	if !f.options.NoHeaders {
+		f.table.SetHeader(contents.Headers)
+	}
+	f.table.AppendBulk(data)
 	f.table.Render()
 
 	return f.buffer.String(), nil
 }
 
+// sortRows returns the rows of a table sorted by a column. Values are compared as numbers or as durations like
+// 5m or 2d3h if all of them are, so columns like Age sort by time rather than text.
+func sortRows(contents TableContents, column string) ([][]string, error) {
+	col := -1
+	for i, header := range contents.Headers {
+		if strings.EqualFold(header, column) {
+			col = i
+			break
+		}
+	}
+	if col < 0 {
+		return nil, fmt.Errorf("no column %q to sort by, must be one of: %s", column, strings.Join(contents.Headers, ", "))
+	}
+
+	rows := make([][]string, len(contents.Data))
+	copy(rows, contents.Data)
+	values := make([]string, 0, len(rows))
+	for _, row := range rows {
+		values = append(values, cell(row, col))
+	}
+	var key func(string) (float64, bool)
+	if parsesAll(values, parseNumber) {
+		key = parseNumber
+	} else if parsesAll(values, parseDuration) {
+		key = parseDuration
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := cell(rows[i], col), cell(rows[j], col)
+		if key == nil {
+			return a < b
+		}
+		x, _ := key(a)
+		y, _ := key(b)
+		return x < y
+	})
+	return rows, nil
+}
+
+func cell(row []string, col int) string {
+	if col < len(row) {
+		return row[col]
+	}
+	return ""
+}
+
+func parsesAll(values []string, parse func(string) (float64, bool)) bool {
+	for _, v := range values {
+		if _, ok := parse(v); !ok {
+			return false
+		}
+	}
+	return len(values) > 0
+}
+
+func parseNumber(s string) (float64, bool) {
+	n, err := strconv.ParseFloat(s, 64)
+	return n, err == nil
+}
+
+// durationUnits are the units of durations as kubectl prints ages
+var durationUnits = map[byte]time.Duration{
+	's': time.Second,
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'y': 365 * 24 * time.Hour,
+}
+
+// parseDuration parses durations like 5m, 2d3h or 3y10d.
+func parseDuration(s string) (float64, bool) {
+	var total time.Duration
+	for s != "" {
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(s) {
+			return 0, false
+		}
+		unit, ok := durationUnits[s[i]]
+		if !ok {
+			return 0, false
+		}
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, false
+		}
+		total += time.Duration(n) * unit
+		s = s[i+1:]
+	}
+	return float64(total), true
+}
+
 func newDefaultTable(buf *bytes.Buffer) *tablewriter.Table {
 	table := tablewriter.NewWriter(buf)
 	table.SetAutoWrapText(false)
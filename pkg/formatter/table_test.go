@@ -1,6 +1,8 @@
 package formatter_test
 
 import (
+	"strings"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/weaveworks/pctl/pkg/formatter"
@@ -33,4 +35,52 @@ var _ = Describe("Table", func() {
 			Expect(err).To(MatchError("func returned wrong type for table formatter. wanted formatter.TableContents"))
 		})
 	})
+
+	When("headers are left out", func() {
+		It("formats only the data", func() {
+			contFunc := func() interface{} {
+				return formatter.TableContents{
+					Headers: []string{"col1", "col2"},
+					Data:    [][]string{{"dat1", "dat2"}},
+				}
+			}
+			out, err := formatter.NewTableFormatter().WithOptions(formatter.TableOptions{NoHeaders: true}).Format(contFunc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("dat1\tdat2\t\n"))
+		})
+	})
+
+	When("rows are sorted by a column", func() {
+		sortBy := func(column string, rows ...[]string) ([]string, error) {
+			contFunc := func() interface{} {
+				return formatter.TableContents{Headers: []string{"Name", "Value"}, Data: rows}
+			}
+			out, err := formatter.NewTableFormatter().WithOptions(formatter.TableOptions{NoHeaders: true, SortBy: column}).Format(contFunc)
+			if err != nil {
+				return nil, err
+			}
+			var names []string
+			for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+				names = append(names, strings.Fields(line)[0])
+			}
+			return names, nil
+		}
+
+		It("sorts text", func() {
+			Expect(sortBy("name", []string{"b", "x"}, []string{"a", "y"})).To(Equal([]string{"a", "b"}))
+		})
+
+		It("sorts numbers numerically", func() {
+			Expect(sortBy("VALUE", []string{"a", "10"}, []string{"b", "9"})).To(Equal([]string{"b", "a"}))
+		})
+
+		It("sorts ages by time", func() {
+			Expect(sortBy("value", []string{"a", "2d3h"}, []string{"b", "5m"}, []string{"c", "40s"})).To(Equal([]string{"c", "b", "a"}))
+		})
+
+		It("returns an error for an unknown column", func() {
+			_, err := sortBy("age", []string{"a", "b"})
+			Expect(err).To(MatchError(`no column "age" to sort by, must be one of: Name, Value`))
+		})
+	})
 })
@@ -0,0 +1,87 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+type jsonPathFormatter struct {
+	parser *jsonpath.JSONPath
+}
+
+// NewJSONPathFormatter formats output with a jsonpath template, such as {.Name} or {[*].Name}. The braces
+// can be left out for a single expression.
+func NewJSONPathFormatter(tmpl string) (jsonPathFormatter, error) {
+	if tmpl == "" {
+		return jsonPathFormatter{}, fmt.Errorf("jsonpath template must be provided, such as jsonpath={.Name}")
+	}
+	if !strings.Contains(tmpl, "{") {
+		tmpl = "{" + tmpl + "}"
+	}
+	parser := jsonpath.New("output")
+	if err := parser.Parse(tmpl); err != nil {
+		return jsonPathFormatter{}, fmt.Errorf("failed to parse jsonpath template: %w", err)
+	}
+	return jsonPathFormatter{parser: parser}, nil
+}
+
+// Format returns the output of the jsonpath template
+func (f jsonPathFormatter) Format(data func() interface{}) (string, error) {
+	obj, err := jsonObject(data())
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	if err := f.parser.Execute(buf, obj); err != nil {
+		return "", fmt.Errorf("failed to execute jsonpath template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+type templateFormatter struct {
+	tmpl *template.Template
+}
+
+// NewTemplateFormatter formats output with a go template, such as {{.Name}}.
+func NewTemplateFormatter(tmpl string) (templateFormatter, error) {
+	if tmpl == "" {
+		return templateFormatter{}, fmt.Errorf("go template must be provided, such as go-template={{.Name}}")
+	}
+	t, err := template.New("output").Parse(tmpl)
+	if err != nil {
+		return templateFormatter{}, fmt.Errorf("failed to parse go template: %w", err)
+	}
+	return templateFormatter{tmpl: t}, nil
+}
+
+// Format returns the output of the go template
+func (f templateFormatter) Format(data func() interface{}) (string, error) {
+	obj, err := jsonObject(data())
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	if err := f.tmpl.Execute(buf, obj); err != nil {
+		return "", fmt.Errorf("failed to execute go template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// jsonObject returns the object of data as it's formatted in json, so templates refer to the same fields as
+// json output shows.
+func jsonObject(data interface{}) (interface{}, error) {
+	out, err := json.Marshal(object(data))
+	if err != nil {
+		return nil, err
+	}
+	var obj interface{}
+	if err := json.Unmarshal(out, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
@@ -0,0 +1,69 @@
+package formatter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/pctl/pkg/formatter"
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+)
+
+var _ = Describe("Templates", func() {
+	dataFunc := func() interface{} {
+		return []profilesv1.ProfileDescription{
+			{Name: "foo", CatalogSource: "bar"},
+			{Name: "baz", CatalogSource: "bar"},
+		}
+	}
+
+	Context("jsonpath", func() {
+		It("formats output with the template, using json field names", func() {
+			f, err := formatter.NewJSONPathFormatter("{range [*]}{.catalog}/{.name}{\"\\n\"}{end}")
+			Expect(err).NotTo(HaveOccurred())
+			out, err := f.Format(dataFunc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("bar/foo\nbar/baz\n"))
+		})
+
+		It("accepts an expression without braces", func() {
+			f, err := formatter.NewJSONPathFormatter("[*].name")
+			Expect(err).NotTo(HaveOccurred())
+			out, err := f.Format(dataFunc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("foo baz"))
+		})
+
+		When("the template is empty", func() {
+			It("returns an error", func() {
+				_, err := formatter.NewJSONPathFormatter("")
+				Expect(err).To(MatchError("jsonpath template must be provided, such as jsonpath={.Name}"))
+			})
+		})
+
+		When("the template refers to a missing field", func() {
+			It("returns an error", func() {
+				f, err := formatter.NewJSONPathFormatter("{[0].missing}")
+				Expect(err).NotTo(HaveOccurred())
+				_, err = f.Format(dataFunc)
+				Expect(err).To(MatchError(ContainSubstring("failed to execute jsonpath template")))
+			})
+		})
+	})
+
+	Context("go-template", func() {
+		It("formats output with the template, using json field names", func() {
+			f, err := formatter.NewTemplateFormatter("{{range .}}{{.name}} {{end}}")
+			Expect(err).NotTo(HaveOccurred())
+			out, err := f.Format(dataFunc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("foo baz "))
+		})
+
+		When("the template can't be parsed", func() {
+			It("returns an error", func() {
+				_, err := formatter.NewTemplateFormatter("{{.name")
+				Expect(err).To(MatchError(ContainSubstring("failed to parse go template")))
+			})
+		})
+	})
+})
@@ -0,0 +1,24 @@
+package formatter
+
+import (
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+type yamlFormatter struct{}
+
+// NewYAMLFormatter formats output into yaml
+func NewYAMLFormatter() yamlFormatter {
+	return yamlFormatter{}
+}
+
+// Format returns the Marshalled yaml output
+func (f yamlFormatter) Format(data func() interface{}) (string, error) {
+	out, err := yaml.Marshal(object(data()))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
@@ -0,0 +1,20 @@
+package formatter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/pctl/pkg/formatter"
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+)
+
+var _ = Describe("YAMLFormatter", func() {
+	It("formats output as yaml", func() {
+		dataFunc := func() interface{} {
+			return profilesv1.ProfileDescription{Name: "foo", CatalogSource: "bar"}
+		}
+		out, err := formatter.NewYAMLFormatter().Format(dataFunc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("catalog: bar\nname: foo"))
+	})
+})
@@ -8,21 +8,178 @@ import (
 )
 
 type FakeSCMClient struct {
-	CreatePullRequestStub        func() error
+	AddAssigneesStub        func(int) error
+	addAssigneesMutex       sync.RWMutex
+	addAssigneesArgsForCall []struct {
+		arg1 int
+	}
+	addAssigneesReturns struct {
+		result1 error
+	}
+	addAssigneesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	AddLabelsStub        func(int) error
+	addLabelsMutex       sync.RWMutex
+	addLabelsArgsForCall []struct {
+		arg1 int
+	}
+	addLabelsReturns struct {
+		result1 error
+	}
+	addLabelsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CreatePullRequestStub        func() (int, error)
 	createPullRequestMutex       sync.RWMutex
 	createPullRequestArgsForCall []struct {
 	}
 	createPullRequestReturns struct {
-		result1 error
+		result1 int
+		result2 error
 	}
 	createPullRequestReturnsOnCall map[int]struct {
+		result1 int
+		result2 error
+	}
+	RequestReviewsStub        func(int) error
+	requestReviewsMutex       sync.RWMutex
+	requestReviewsArgsForCall []struct {
+		arg1 int
+	}
+	requestReviewsReturns struct {
+		result1 error
+	}
+	requestReviewsReturnsOnCall map[int]struct {
 		result1 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeSCMClient) CreatePullRequest() error {
+func (fake *FakeSCMClient) AddAssignees(arg1 int) error {
+	fake.addAssigneesMutex.Lock()
+	ret, specificReturn := fake.addAssigneesReturnsOnCall[len(fake.addAssigneesArgsForCall)]
+	fake.addAssigneesArgsForCall = append(fake.addAssigneesArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.AddAssigneesStub
+	fakeReturns := fake.addAssigneesReturns
+	fake.recordInvocation("AddAssignees", []interface{}{arg1})
+	fake.addAssigneesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSCMClient) AddAssigneesCallCount() int {
+	fake.addAssigneesMutex.RLock()
+	defer fake.addAssigneesMutex.RUnlock()
+	return len(fake.addAssigneesArgsForCall)
+}
+
+func (fake *FakeSCMClient) AddAssigneesCalls(stub func(int) error) {
+	fake.addAssigneesMutex.Lock()
+	defer fake.addAssigneesMutex.Unlock()
+	fake.AddAssigneesStub = stub
+}
+
+func (fake *FakeSCMClient) AddAssigneesArgsForCall(i int) int {
+	fake.addAssigneesMutex.RLock()
+	defer fake.addAssigneesMutex.RUnlock()
+	argsForCall := fake.addAssigneesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSCMClient) AddAssigneesReturns(result1 error) {
+	fake.addAssigneesMutex.Lock()
+	defer fake.addAssigneesMutex.Unlock()
+	fake.AddAssigneesStub = nil
+	fake.addAssigneesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSCMClient) AddAssigneesReturnsOnCall(i int, result1 error) {
+	fake.addAssigneesMutex.Lock()
+	defer fake.addAssigneesMutex.Unlock()
+	fake.AddAssigneesStub = nil
+	if fake.addAssigneesReturnsOnCall == nil {
+		fake.addAssigneesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.addAssigneesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSCMClient) AddLabels(arg1 int) error {
+	fake.addLabelsMutex.Lock()
+	ret, specificReturn := fake.addLabelsReturnsOnCall[len(fake.addLabelsArgsForCall)]
+	fake.addLabelsArgsForCall = append(fake.addLabelsArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.AddLabelsStub
+	fakeReturns := fake.addLabelsReturns
+	fake.recordInvocation("AddLabels", []interface{}{arg1})
+	fake.addLabelsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSCMClient) AddLabelsCallCount() int {
+	fake.addLabelsMutex.RLock()
+	defer fake.addLabelsMutex.RUnlock()
+	return len(fake.addLabelsArgsForCall)
+}
+
+func (fake *FakeSCMClient) AddLabelsCalls(stub func(int) error) {
+	fake.addLabelsMutex.Lock()
+	defer fake.addLabelsMutex.Unlock()
+	fake.AddLabelsStub = stub
+}
+
+func (fake *FakeSCMClient) AddLabelsArgsForCall(i int) int {
+	fake.addLabelsMutex.RLock()
+	defer fake.addLabelsMutex.RUnlock()
+	argsForCall := fake.addLabelsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSCMClient) AddLabelsReturns(result1 error) {
+	fake.addLabelsMutex.Lock()
+	defer fake.addLabelsMutex.Unlock()
+	fake.AddLabelsStub = nil
+	fake.addLabelsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSCMClient) AddLabelsReturnsOnCall(i int, result1 error) {
+	fake.addLabelsMutex.Lock()
+	defer fake.addLabelsMutex.Unlock()
+	fake.AddLabelsStub = nil
+	if fake.addLabelsReturnsOnCall == nil {
+		fake.addLabelsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.addLabelsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSCMClient) CreatePullRequest() (int, error) {
 	fake.createPullRequestMutex.Lock()
 	ret, specificReturn := fake.createPullRequestReturnsOnCall[len(fake.createPullRequestArgsForCall)]
 	fake.createPullRequestArgsForCall = append(fake.createPullRequestArgsForCall, struct {
@@ -35,9 +192,9 @@ func (fake *FakeSCMClient) CreatePullRequest() error {
 		return stub()
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fakeReturns.result1
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeSCMClient) CreatePullRequestCallCount() int {
@@ -46,31 +203,95 @@ func (fake *FakeSCMClient) CreatePullRequestCallCount() int {
 	return len(fake.createPullRequestArgsForCall)
 }
 
-func (fake *FakeSCMClient) CreatePullRequestCalls(stub func() error) {
+func (fake *FakeSCMClient) CreatePullRequestCalls(stub func() (int, error)) {
 	fake.createPullRequestMutex.Lock()
 	defer fake.createPullRequestMutex.Unlock()
 	fake.CreatePullRequestStub = stub
 }
 
-func (fake *FakeSCMClient) CreatePullRequestReturns(result1 error) {
+func (fake *FakeSCMClient) CreatePullRequestReturns(result1 int, result2 error) {
 	fake.createPullRequestMutex.Lock()
 	defer fake.createPullRequestMutex.Unlock()
 	fake.CreatePullRequestStub = nil
 	fake.createPullRequestReturns = struct {
-		result1 error
-	}{result1}
+		result1 int
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeSCMClient) CreatePullRequestReturnsOnCall(i int, result1 error) {
+func (fake *FakeSCMClient) CreatePullRequestReturnsOnCall(i int, result1 int, result2 error) {
 	fake.createPullRequestMutex.Lock()
 	defer fake.createPullRequestMutex.Unlock()
 	fake.CreatePullRequestStub = nil
 	if fake.createPullRequestReturnsOnCall == nil {
 		fake.createPullRequestReturnsOnCall = make(map[int]struct {
-			result1 error
+			result1 int
+			result2 error
 		})
 	}
 	fake.createPullRequestReturnsOnCall[i] = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSCMClient) RequestReviews(arg1 int) error {
+	fake.requestReviewsMutex.Lock()
+	ret, specificReturn := fake.requestReviewsReturnsOnCall[len(fake.requestReviewsArgsForCall)]
+	fake.requestReviewsArgsForCall = append(fake.requestReviewsArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.RequestReviewsStub
+	fakeReturns := fake.requestReviewsReturns
+	fake.recordInvocation("RequestReviews", []interface{}{arg1})
+	fake.requestReviewsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSCMClient) RequestReviewsCallCount() int {
+	fake.requestReviewsMutex.RLock()
+	defer fake.requestReviewsMutex.RUnlock()
+	return len(fake.requestReviewsArgsForCall)
+}
+
+func (fake *FakeSCMClient) RequestReviewsCalls(stub func(int) error) {
+	fake.requestReviewsMutex.Lock()
+	defer fake.requestReviewsMutex.Unlock()
+	fake.RequestReviewsStub = stub
+}
+
+func (fake *FakeSCMClient) RequestReviewsArgsForCall(i int) int {
+	fake.requestReviewsMutex.RLock()
+	defer fake.requestReviewsMutex.RUnlock()
+	argsForCall := fake.requestReviewsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSCMClient) RequestReviewsReturns(result1 error) {
+	fake.requestReviewsMutex.Lock()
+	defer fake.requestReviewsMutex.Unlock()
+	fake.RequestReviewsStub = nil
+	fake.requestReviewsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSCMClient) RequestReviewsReturnsOnCall(i int, result1 error) {
+	fake.requestReviewsMutex.Lock()
+	defer fake.requestReviewsMutex.Unlock()
+	fake.RequestReviewsStub = nil
+	if fake.requestReviewsReturnsOnCall == nil {
+		fake.requestReviewsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.requestReviewsReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
@@ -78,8 +299,14 @@ func (fake *FakeSCMClient) CreatePullRequestReturnsOnCall(i int, result1 error)
 func (fake *FakeSCMClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.addAssigneesMutex.RLock()
+	defer fake.addAssigneesMutex.RUnlock()
+	fake.addLabelsMutex.RLock()
+	defer fake.addLabelsMutex.RUnlock()
 	fake.createPullRequestMutex.RLock()
 	defer fake.createPullRequestMutex.RUnlock()
+	fake.requestReviewsMutex.RLock()
+	defer fake.requestReviewsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
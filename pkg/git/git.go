@@ -5,22 +5,29 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/weaveworks/pctl/pkg/runner"
 )
 
 const (
 	gitCmd = "git"
+	// DefaultCommitMessage is used if no commit message is configured.
+	DefaultCommitMessage = "Push changes to remote"
+	// SignGPG signs commits with a GPG key.
+	SignGPG = "gpg"
+	// SignSSH signs commits with an SSH key.
+	SignSSH = "ssh"
 )
 
 // Git defines high level abilities for Git related operations.
 //go:generate counterfeiter -o fakes/fake_git.go . Git
 type Git interface {
-	// Add staged changes.
+	// Add stages additions, modifications and removals.
 	Add() error
-	// Commit changes.
+	// Commit staged changes.
 	Commit() error
-	// CreateBranch create a branch if it's needed.
+	// CreateBranch create a branch if it's needed or checks out an existing one.
 	CreateBranch() error
 	// CreateRepository bootstraps a plain repository at a given location.
 	CreateRepository() error
@@ -34,11 +41,19 @@ type Git interface {
 
 // CLIGitConfig defines configuration options for CLIGit.
 type CLIGitConfig struct {
-	Filename string
+	// Paths limits adding, committing and detecting changes to these files or directories.
+	// If empty, the whole work tree is used.
+	Paths    []string
 	Location string
 	Branch   string
 	Remote   string
 	Base     string
+	Message  string
+	// AuthorName and AuthorEmail override the identity used for commits if set.
+	AuthorName  string
+	AuthorEmail string
+	// Sign signs commits with the configured signing key of the user. Either SignGPG or SignSSH.
+	Sign string
 }
 
 // CLIGit is a new command line based Git.
@@ -49,6 +64,9 @@ type CLIGit struct {
 
 // NewCLIGit creates a new command line based Git.
 func NewCLIGit(cfg CLIGitConfig, r runner.Runner) *CLIGit {
+	if cfg.Message == "" {
+		cfg.Message = DefaultCommitMessage
+	}
 	return &CLIGit{
 		CLIGitConfig: cfg,
 		Runner:       r,
@@ -67,39 +85,149 @@ func (g *CLIGit) Commit() error {
 	if !hasChanges {
 		return nil
 	}
-	args := []string{
+	var args []string
+	if g.AuthorName != "" {
+		args = append(args, "-c", "user.name="+g.AuthorName)
+	}
+	if g.AuthorEmail != "" {
+		args = append(args, "-c", "user.email="+g.AuthorEmail)
+	}
+	switch g.Sign {
+	case "":
+	case SignGPG:
+		args = append(args, "-c", "gpg.format=openpgp")
+	case SignSSH:
+		args = append(args, "-c", "gpg.format=ssh")
+	default:
+		return fmt.Errorf("unsupported signing method %q, must be %s or %s", g.Sign, SignGPG, SignSSH)
+	}
+	args = append(args,
 		"--git-dir", filepath.Join(g.Location, ".git"),
 		"--work-tree", g.Location,
 		"commit",
 		"-m",
-		"Push changes to remote",
-		g.Filename,
+		g.Message,
+	)
+	if g.Sign != "" {
+		args = append(args, "--gpg-sign")
 	}
+	args = append(args, g.pathspec()...)
 	if err := g.runGitCmd(args...); err != nil {
 		return fmt.Errorf("failed to run commit: %w", err)
 	}
 	return nil
 }
 
-// CreateBranch creates a branch if it differs from the base.
+// CreateBranch creates a branch if it differs from the base. If the branch already exists, locally or on the remote,
+// it is checked out instead.
 func (g *CLIGit) CreateBranch() error {
 	if g.Base == g.Branch {
 		return nil
 	}
-	fmt.Println("creating new branch")
+	exists, err := g.branchExists()
+	if err != nil {
+		return fmt.Errorf("failed to check if branch %s exists: %w", g.Branch, err)
+	}
 	args := []string{
 		"--git-dir", filepath.Join(g.Location, ".git"),
 		"--work-tree", g.Location,
 		"checkout",
-		"-b",
-		g.Branch,
 	}
+	if exists {
+		fmt.Println("checking out existing branch")
+		args = append(args, g.Branch)
+		if err := g.runGitCmd(args...); err != nil {
+			return fmt.Errorf("failed to check out branch %s: %w", g.Branch, err)
+		}
+		return nil
+	}
+	remoteExists, err := g.remoteBranchExists()
+	if err != nil {
+		return fmt.Errorf("failed to check if branch %s exists on remote %s: %w", g.Branch, g.Remote, err)
+	}
+	if remoteExists {
+		// new commits have to build on the branch pushed by an earlier run, or pushing them is rejected
+		fmt.Println("checking out existing remote branch")
+		if err := g.fetchBranch(); err != nil {
+			return err
+		}
+		// a single-branch clone doesn't fetch the branch by default, which git requires to set up tracking
+		args = []string{
+			"--git-dir", filepath.Join(g.Location, ".git"),
+			"--work-tree", g.Location,
+			"-c", fmt.Sprintf("remote.%s.fetch=%s", g.Remote, g.branchRefspec()),
+			"checkout",
+			"-b", g.Branch,
+			"--track", g.Remote + "/" + g.Branch,
+		}
+		if err := g.runGitCmd(args...); err != nil {
+			return fmt.Errorf("failed to check out remote branch %s/%s: %w", g.Remote, g.Branch, err)
+		}
+		return nil
+	}
+	fmt.Println("creating new branch")
+	args = append(args, "-b", g.Branch)
 	if err := g.runGitCmd(args...); err != nil {
 		return fmt.Errorf("failed to create new branch %s: %w", g.Branch, err)
 	}
 	return nil
 }
 
+// branchExists returns whether the configured branch already exists locally.
+func (g *CLIGit) branchExists() (bool, error) {
+	args := []string{
+		"--git-dir", filepath.Join(g.Location, ".git"),
+		"--work-tree", g.Location,
+		"branch",
+		"--list",
+		g.Branch,
+	}
+	out, err := g.Runner.Run(gitCmd, args...)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(out)) != "", nil
+}
+
+// remoteBranchExists returns whether the configured branch exists on the remote. The remote is asked rather than
+// the local remote-tracking branches, which a fresh or shallow clone may not have.
+func (g *CLIGit) remoteBranchExists() (bool, error) {
+	if g.Remote == "" {
+		return false, nil
+	}
+	args := []string{
+		"--git-dir", filepath.Join(g.Location, ".git"),
+		"ls-remote",
+		"--heads",
+		g.Remote,
+		g.Branch,
+	}
+	out, err := g.Runner.Run(gitCmd, args...)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(out)) != "", nil
+}
+
+// fetchBranch fetches the configured branch into its remote-tracking branch.
+func (g *CLIGit) fetchBranch() error {
+	args := []string{
+		"--git-dir", filepath.Join(g.Location, ".git"),
+		"fetch",
+		g.Remote,
+		g.branchRefspec(),
+	}
+	if err := g.runGitCmd(args...); err != nil {
+		return fmt.Errorf("failed to fetch branch %s from remote %s: %w", g.Branch, g.Remote, err)
+	}
+	return nil
+}
+
+// branchRefspec maps the configured branch on the remote to its remote-tracking branch.
+func (g *CLIGit) branchRefspec() string {
+	return fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", g.Branch, g.Remote, g.Branch)
+}
+
 // CreateRepository bootstraps a plain repository at a given location.
 func (g *CLIGit) CreateRepository() error {
 	return errors.New("implement me")
@@ -116,7 +244,7 @@ func (g *CLIGit) IsRepository() error {
 	return nil
 }
 
-// HasChanges returns whether a location has uncommitted changes or not.
+// HasChanges returns whether the configured paths have uncommitted changes or not.
 func (g *CLIGit) HasChanges() (bool, error) {
 	args := []string{
 		"--git-dir", filepath.Join(g.Location, ".git"),
@@ -124,6 +252,7 @@ func (g *CLIGit) HasChanges() (bool, error) {
 		"status",
 		"-s",
 	}
+	args = append(args, g.pathspec()...)
 	out, err := g.Runner.Run(gitCmd, args...)
 	if err != nil {
 		return false, fmt.Errorf("failed to check if there are changes: %w", err)
@@ -147,21 +276,46 @@ func (g *CLIGit) Push() error {
 	return nil
 }
 
-// Add will add any changes to the generated file.
+// RemoteURL returns the url of the configured remote.
+func (g *CLIGit) RemoteURL() (string, error) {
+	args := []string{
+		"--git-dir", filepath.Join(g.Location, ".git"),
+		"--work-tree", g.Location,
+		"remote",
+		"get-url",
+		g.Remote,
+	}
+	out, err := g.Runner.Run(gitCmd, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get url of remote %s: %w", g.Remote, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Add will stage any additions, modifications and removals in the configured paths.
 func (g *CLIGit) Add() error {
 	fmt.Println("adding unstaged changes")
 	args := []string{
 		"--git-dir", filepath.Join(g.Location, ".git"),
 		"--work-tree", g.Location,
 		"add",
-		g.Filename,
+		"--all",
 	}
+	args = append(args, g.pathspec()...)
 	if err := g.runGitCmd(args...); err != nil {
 		return fmt.Errorf("failed to run add: %w", err)
 	}
 	return nil
 }
 
+// pathspec returns the arguments which limit a git command to the configured paths.
+func (g *CLIGit) pathspec() []string {
+	if len(g.Paths) == 0 {
+		return nil
+	}
+	return append([]string{"--"}, g.Paths...)
+}
+
 // runGitCmd is a convenient wrapper around running commands with error output when the output is not needed but needs to
 // be logged.
 func (g *CLIGit) runGitCmd(args ...string) error {
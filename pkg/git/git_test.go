@@ -4,12 +4,15 @@ import (
 	"errors"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/weaveworks/pctl/pkg/git"
+	pctlrunner "github.com/weaveworks/pctl/pkg/runner"
 	"github.com/weaveworks/pctl/pkg/runner/fakes"
 )
 
@@ -27,7 +30,7 @@ var _ = Describe("git", func() {
 			It("returns false and a sensible wrapped error", func() {
 				runner.RunReturns([]byte(""), errors.New("nope"))
 				g := git.NewCLIGit(git.CLIGitConfig{
-					Filename: "filename",
+					Paths:    []string{"filename"},
 					Location: "location",
 					Branch:   "main",
 					Remote:   "origin",
@@ -38,7 +41,7 @@ var _ = Describe("git", func() {
 				Expect(runner.RunCallCount()).To(Equal(1))
 				arg, args := runner.RunArgsForCall(0)
 				Expect(arg).To(Equal("git"))
-				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "status", "-s"}))
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "status", "-s", "--", "filename"}))
 			})
 		})
 		When("normal flow operations", func() {
@@ -57,10 +60,24 @@ var _ = Describe("git", func() {
 				Expect(arg).To(Equal("git"))
 				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "status", "-s"}))
 			})
+			It("only checks the configured paths for changes", func() {
+				runner.RunReturns([]byte(" D weaveworks-nginx/HelmRelease-1.yaml"), nil)
+				g := git.NewCLIGit(git.CLIGitConfig{
+					Paths:    []string{"weaveworks-nginx", "README.md"},
+					Location: "location",
+					Branch:   "main",
+					Remote:   "origin",
+				}, runner)
+				ok, err := g.HasChanges()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeTrue())
+				_, args := runner.RunArgsForCall(0)
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "status", "-s", "--", "weaveworks-nginx", "README.md"}))
+			})
 			It("detects if there are no changes if stats returns empty", func() {
 				runner.RunReturns([]byte(""), nil)
 				g := git.NewCLIGit(git.CLIGitConfig{
-					Filename: "filename",
+					Paths:    []string{"filename"},
 					Location: "location",
 					Branch:   "main",
 					Remote:   "origin",
@@ -71,7 +88,7 @@ var _ = Describe("git", func() {
 				Expect(runner.RunCallCount()).To(Equal(1))
 				arg, args := runner.RunArgsForCall(0)
 				Expect(arg).To(Equal("git"))
-				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "status", "-s"}))
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "status", "-s", "--", "filename"}))
 			})
 		})
 	})
@@ -80,7 +97,7 @@ var _ = Describe("git", func() {
 		When("normal flow operations", func() {
 			It("can add changes to a commit", func() {
 				g := git.NewCLIGit(git.CLIGitConfig{
-					Filename: "filename",
+					Paths:    []string{"filename"},
 					Location: "location",
 					Branch:   "main",
 					Remote:   "origin",
@@ -90,14 +107,14 @@ var _ = Describe("git", func() {
 				Expect(runner.RunCallCount()).To(Equal(1))
 				arg, args := runner.RunArgsForCall(0)
 				Expect(arg).To(Equal("git"))
-				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "add", "filename"}))
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "add", "--all", "--", "filename"}))
 			})
 		})
 		When("the flow is disrupted with errors", func() {
 			It("returns a sensible wrapped error", func() {
 				runner.RunReturns([]byte(""), errors.New("nope"))
 				g := git.NewCLIGit(git.CLIGitConfig{
-					Filename: "filename",
+					Paths:    []string{"filename"},
 					Location: "location",
 					Branch:   "main",
 					Remote:   "origin",
@@ -107,7 +124,7 @@ var _ = Describe("git", func() {
 				Expect(runner.RunCallCount()).To(Equal(1))
 				arg, args := runner.RunArgsForCall(0)
 				Expect(arg).To(Equal("git"))
-				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "add", "filename"}))
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "add", "--all", "--", "filename"}))
 			})
 		})
 	})
@@ -116,7 +133,7 @@ var _ = Describe("git", func() {
 		When("normal flow operations", func() {
 			It("pushes changes to a remote", func() {
 				g := git.NewCLIGit(git.CLIGitConfig{
-					Filename: "filename",
+					Paths:    []string{"filename"},
 					Location: "location",
 					Branch:   "main",
 					Remote:   "origin",
@@ -133,7 +150,7 @@ var _ = Describe("git", func() {
 			It("returns a sensible wrapped error", func() {
 				runner.RunReturns([]byte(""), errors.New("nope"))
 				g := git.NewCLIGit(git.CLIGitConfig{
-					Filename: "filename",
+					Paths:    []string{"filename"},
 					Location: "location",
 					Branch:   "main",
 					Remote:   "origin",
@@ -153,7 +170,7 @@ var _ = Describe("git", func() {
 			It("commit changes", func() {
 				runner.RunReturnsOnCall(0, []byte("profile_subscription.yaml"), nil)
 				g := git.NewCLIGit(git.CLIGitConfig{
-					Filename: "filename",
+					Paths:    []string{"filename"},
 					Location: "location",
 					Branch:   "main",
 					Remote:   "origin",
@@ -163,10 +180,79 @@ var _ = Describe("git", func() {
 				Expect(runner.RunCallCount()).To(Equal(2))
 				arg, args := runner.RunArgsForCall(0)
 				Expect(arg).To(Equal("git"))
-				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "status", "-s"}))
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "status", "-s", "--", "filename"}))
 				arg, args = runner.RunArgsForCall(1)
 				Expect(arg).To(Equal("git"))
-				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "commit", "-m", "Push changes to remote", "filename"}))
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "commit", "-m", "Push changes to remote", "--", "filename"}))
+			})
+		})
+		When("a commit message is configured", func() {
+			It("commits with that message", func() {
+				runner.RunReturnsOnCall(0, []byte("profile_subscription.yaml"), nil)
+				g := git.NewCLIGit(git.CLIGitConfig{
+					Paths:    []string{"filename"},
+					Location: "location",
+					Branch:   "main",
+					Remote:   "origin",
+					Message:  "Install profile nginx",
+				}, runner)
+				err := g.Commit()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(runner.RunCallCount()).To(Equal(2))
+				_, args := runner.RunArgsForCall(1)
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "commit", "-m", "Install profile nginx", "--", "filename"}))
+			})
+		})
+		When("an author is configured", func() {
+			It("commits with that identity", func() {
+				runner.RunReturnsOnCall(0, []byte("profile_subscription.yaml"), nil)
+				g := git.NewCLIGit(git.CLIGitConfig{
+					Paths:       []string{"filename"},
+					Location:    "location",
+					AuthorName:  "pctl-bot",
+					AuthorEmail: "pctl-bot@weave.works",
+				}, runner)
+				err := g.Commit()
+				Expect(err).NotTo(HaveOccurred())
+				_, args := runner.RunArgsForCall(1)
+				Expect(args).To(Equal([]string{"-c", "user.name=pctl-bot", "-c", "user.email=pctl-bot@weave.works", "--git-dir", "location/.git", "--work-tree", "location", "commit", "-m", "Push changes to remote", "--", "filename"}))
+			})
+		})
+		When("signing is configured", func() {
+			It("signs the commit with gpg", func() {
+				runner.RunReturnsOnCall(0, []byte("profile_subscription.yaml"), nil)
+				g := git.NewCLIGit(git.CLIGitConfig{
+					Paths:    []string{"filename"},
+					Location: "location",
+					Sign:     git.SignGPG,
+				}, runner)
+				err := g.Commit()
+				Expect(err).NotTo(HaveOccurred())
+				_, args := runner.RunArgsForCall(1)
+				Expect(args).To(Equal([]string{"-c", "gpg.format=openpgp", "--git-dir", "location/.git", "--work-tree", "location", "commit", "-m", "Push changes to remote", "--gpg-sign", "--", "filename"}))
+			})
+			It("signs the commit with ssh", func() {
+				runner.RunReturnsOnCall(0, []byte("profile_subscription.yaml"), nil)
+				g := git.NewCLIGit(git.CLIGitConfig{
+					Paths:    []string{"filename"},
+					Location: "location",
+					Sign:     git.SignSSH,
+				}, runner)
+				err := g.Commit()
+				Expect(err).NotTo(HaveOccurred())
+				_, args := runner.RunArgsForCall(1)
+				Expect(args).To(Equal([]string{"-c", "gpg.format=ssh", "--git-dir", "location/.git", "--work-tree", "location", "commit", "-m", "Push changes to remote", "--gpg-sign", "--", "filename"}))
+			})
+			It("returns an error for unknown signing methods", func() {
+				runner.RunReturnsOnCall(0, []byte("profile_subscription.yaml"), nil)
+				g := git.NewCLIGit(git.CLIGitConfig{
+					Paths:    []string{"filename"},
+					Location: "location",
+					Sign:     "x509",
+				}, runner)
+				err := g.Commit()
+				Expect(err).To(MatchError(`unsupported signing method "x509", must be gpg or ssh`))
+				Expect(runner.RunCallCount()).To(Equal(1))
 			})
 		})
 		When("the flow is disrupted with errors", func() {
@@ -174,7 +260,7 @@ var _ = Describe("git", func() {
 				runner.RunReturnsOnCall(0, []byte("profile_subscription.yaml"), nil)
 				runner.RunReturnsOnCall(1, []byte(""), errors.New("nope"))
 				g := git.NewCLIGit(git.CLIGitConfig{
-					Filename: "filename",
+					Paths:    []string{"filename"},
 					Location: "location",
 					Branch:   "main",
 					Remote:   "origin",
@@ -184,10 +270,10 @@ var _ = Describe("git", func() {
 				Expect(runner.RunCallCount()).To(Equal(2))
 				arg, args := runner.RunArgsForCall(0)
 				Expect(arg).To(Equal("git"))
-				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "status", "-s"}))
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "status", "-s", "--", "filename"}))
 				arg, args = runner.RunArgsForCall(1)
 				Expect(arg).To(Equal("git"))
-				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "commit", "-m", "Push changes to remote", "filename"}))
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "commit", "-m", "Push changes to remote", "--", "filename"}))
 			})
 		})
 	})
@@ -196,7 +282,7 @@ var _ = Describe("git", func() {
 		When("normal flow operations", func() {
 			It("creates a branch if it differs from base", func() {
 				g := git.NewCLIGit(git.CLIGitConfig{
-					Filename: "filename",
+					Paths:    []string{"filename"},
 					Location: "location",
 					Branch:   "test01",
 					Remote:   "origin",
@@ -204,15 +290,53 @@ var _ = Describe("git", func() {
 				}, runner)
 				err := g.CreateBranch()
 				Expect(err).NotTo(HaveOccurred())
-				Expect(runner.RunCallCount()).To(Equal(1))
+				Expect(runner.RunCallCount()).To(Equal(3))
 				arg, args := runner.RunArgsForCall(0)
 				Expect(arg).To(Equal("git"))
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "branch", "--list", "test01"}))
+				arg, args = runner.RunArgsForCall(1)
+				Expect(arg).To(Equal("git"))
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "ls-remote", "--heads", "origin", "test01"}))
+				arg, args = runner.RunArgsForCall(2)
+				Expect(arg).To(Equal("git"))
 				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "checkout", "-b", "test01"}))
-
+			})
+			It("checks out the branch tracking the remote if it only exists there", func() {
+				runner.RunReturnsOnCall(1, []byte("abc123\trefs/heads/test01\n"), nil)
+				g := git.NewCLIGit(git.CLIGitConfig{
+					Paths:    []string{"filename"},
+					Location: "location",
+					Branch:   "test01",
+					Remote:   "origin",
+					Base:     "main",
+				}, runner)
+				err := g.CreateBranch()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(runner.RunCallCount()).To(Equal(4))
+				_, args := runner.RunArgsForCall(2)
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "fetch", "origin", "+refs/heads/test01:refs/remotes/origin/test01"}))
+				_, args = runner.RunArgsForCall(3)
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "-c", "remote.origin.fetch=+refs/heads/test01:refs/remotes/origin/test01", "checkout", "-b", "test01", "--track", "origin/test01"}))
+			})
+			It("checks out the branch if it already exists", func() {
+				runner.RunReturnsOnCall(0, []byte("  test01\n"), nil)
+				g := git.NewCLIGit(git.CLIGitConfig{
+					Paths:    []string{"filename"},
+					Location: "location",
+					Branch:   "test01",
+					Remote:   "origin",
+					Base:     "main",
+				}, runner)
+				err := g.CreateBranch()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(runner.RunCallCount()).To(Equal(2))
+				arg, args := runner.RunArgsForCall(1)
+				Expect(arg).To(Equal("git"))
+				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "checkout", "test01"}))
 			})
 			It("doesn't do anything if the branch equals the base", func() {
 				g := git.NewCLIGit(git.CLIGitConfig{
-					Filename: "filename",
+					Paths:    []string{"filename"},
 					Location: "location",
 					Branch:   "main",
 					Remote:   "origin",
@@ -225,9 +349,9 @@ var _ = Describe("git", func() {
 		})
 		When("the flow is disrupted with errors", func() {
 			It("returns a sensible wrapped error", func() {
-				runner.RunReturns([]byte(""), errors.New("nope"))
+				runner.RunReturnsOnCall(2, []byte(""), errors.New("nope"))
 				g := git.NewCLIGit(git.CLIGitConfig{
-					Filename: "filename",
+					Paths:    []string{"filename"},
 					Location: "location",
 					Branch:   "test01",
 					Remote:   "origin",
@@ -235,11 +359,95 @@ var _ = Describe("git", func() {
 				}, runner)
 				err := g.CreateBranch()
 				Expect(err).To(MatchError(`failed to create new branch test01: nope`))
-				Expect(runner.RunCallCount()).To(Equal(1))
-				arg, args := runner.RunArgsForCall(0)
+				Expect(runner.RunCallCount()).To(Equal(3))
+				arg, args := runner.RunArgsForCall(2)
 				Expect(arg).To(Equal("git"))
 				Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "checkout", "-b", "test01"}))
 			})
+			It("returns an error if the existing branches can't be listed", func() {
+				runner.RunReturns([]byte(""), errors.New("nope"))
+				g := git.NewCLIGit(git.CLIGitConfig{
+					Paths:    []string{"filename"},
+					Location: "location",
+					Branch:   "test01",
+					Remote:   "origin",
+					Base:     "main",
+				}, runner)
+				err := g.CreateBranch()
+				Expect(err).To(MatchError(`failed to check if branch test01 exists: nope`))
+				Expect(runner.RunCallCount()).To(Equal(1))
+			})
+			It("returns an error if the remote can't be asked for the branch", func() {
+				runner.RunReturnsOnCall(1, []byte(""), errors.New("nope"))
+				g := git.NewCLIGit(git.CLIGitConfig{
+					Paths:    []string{"filename"},
+					Location: "location",
+					Branch:   "test01",
+					Remote:   "origin",
+					Base:     "main",
+				}, runner)
+				err := g.CreateBranch()
+				Expect(err).To(MatchError(`failed to check if branch test01 exists on remote origin: nope`))
+				Expect(runner.RunCallCount()).To(Equal(2))
+			})
+			It("returns an error if the existing branch can't be checked out", func() {
+				runner.RunReturnsOnCall(0, []byte("  test01\n"), nil)
+				runner.RunReturnsOnCall(1, []byte(""), errors.New("nope"))
+				g := git.NewCLIGit(git.CLIGitConfig{
+					Paths:    []string{"filename"},
+					Location: "location",
+					Branch:   "test01",
+					Remote:   "origin",
+					Base:     "main",
+				}, runner)
+				err := g.CreateBranch()
+				Expect(err).To(MatchError(`failed to check out branch test01: nope`))
+			})
+		})
+	})
+
+	Context("CreateBranch with a branch only on the remote", func() {
+		It("builds on the history of the remote branch so the push is a fast-forward", func() {
+			tmp, err := ioutil.TempDir("", "create_branch_remote")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(tmp)
+			remote, earlier, clone := filepath.Join(tmp, "remote.git"), filepath.Join(tmp, "earlier"), filepath.Join(tmp, "clone")
+			gitRun := func(dir string, args ...string) {
+				out, err := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=pctl", "-c", "user.email=pctl@weave.works"}, args...)...).CombinedOutput()
+				Expect(err).NotTo(HaveOccurred(), string(out))
+			}
+			gitRun(tmp, "init", "-q", "--bare", remote)
+			gitRun(tmp, "clone", "-q", remote, earlier)
+			gitRun(earlier, "checkout", "-q", "-b", "main")
+			gitRun(earlier, "commit", "-q", "--allow-empty", "-m", "initial")
+			gitRun(earlier, "push", "-q", "origin", "main")
+			// an earlier run pushed the branch from another clone
+			gitRun(earlier, "checkout", "-q", "-b", "pctl-update")
+			gitRun(earlier, "commit", "-q", "--allow-empty", "-m", "earlier run")
+			gitRun(earlier, "push", "-q", "origin", "pctl-update")
+			gitRun(tmp, "clone", "-q", "--single-branch", "--branch", "main", remote, clone)
+
+			Expect(ioutil.WriteFile(filepath.Join(clone, "profile.yaml"), []byte("v2"), 0644)).To(Succeed())
+			g := git.NewCLIGit(git.CLIGitConfig{
+				Location:    clone,
+				Branch:      "pctl-update",
+				Remote:      "origin",
+				Base:        "main",
+				AuthorName:  "pctl",
+				AuthorEmail: "pctl@weave.works",
+			}, &pctlrunner.CLIRunner{})
+			Expect(g.CreateBranch()).To(Succeed())
+			Expect(g.Add()).To(Succeed())
+			Expect(g.Commit()).To(Succeed())
+			Expect(g.Push()).To(Succeed())
+			out, err := exec.Command("git", "-C", clone, "config", "--get-regexp", `branch\.pctl-update\.`).CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(out))
+			Expect(string(out)).To(Equal("branch.pctl-update.remote origin\nbranch.pctl-update.merge refs/heads/pctl-update\n"))
+
+			gitRun(earlier, "fetch", "-q", "origin")
+			out, err = exec.Command("git", "-C", earlier, "log", "--format=%s", "origin/pctl-update").CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(out))
+			Expect(string(out)).To(Equal("Push changes to remote\nearlier run\ninitial\n"))
 		})
 	})
 
@@ -247,7 +455,7 @@ var _ = Describe("git", func() {
 		When("the flow is disrupted with errors", func() {
 			It("return a sensible error", func() {
 				g := git.NewCLIGit(git.CLIGitConfig{
-					Filename: "filename",
+					Paths:    []string{"filename"},
 					Location: "notexists",
 					Branch:   "main",
 					Remote:   "origin",
@@ -264,7 +472,7 @@ var _ = Describe("git", func() {
 				err = os.Mkdir(filepath.Join(tmp, ".git"), os.ModeDir)
 				Expect(err).NotTo(HaveOccurred())
 				g := git.NewCLIGit(git.CLIGitConfig{
-					Filename: "filename",
+					Paths:    []string{"filename"},
 					Location: tmp,
 					Branch:   "main",
 					Remote:   "origin",
@@ -277,7 +485,7 @@ var _ = Describe("git", func() {
 				tmp, err := ioutil.TempDir("", "detect_git_repo_02")
 				Expect(err).NotTo(HaveOccurred())
 				g := git.NewCLIGit(git.CLIGitConfig{
-					Filename: "filename",
+					Paths:    []string{"filename"},
 					Location: tmp,
 					Branch:   "main",
 					Remote:   "origin",
@@ -288,4 +496,77 @@ var _ = Describe("git", func() {
 			})
 		})
 	})
+
+	Context("scoped paths", func() {
+		It("adds and commits additions, modifications and removals only in the configured paths", func() {
+			tmp, err := ioutil.TempDir("", "scoped_paths_01")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(tmp)
+			profileDir := filepath.Join(tmp, "weaveworks-nginx")
+			Expect(os.Mkdir(profileDir, 0755)).To(Succeed())
+			for _, f := range []string{"weaveworks-nginx/profile.yaml", "weaveworks-nginx/HelmRelease-1.yaml", "unrelated.yaml"} {
+				Expect(ioutil.WriteFile(filepath.Join(tmp, f), []byte("v1"), 0644)).To(Succeed())
+			}
+			for _, args := range [][]string{
+				{"init", "-q"},
+				{"config", "user.name", "pctl"},
+				{"config", "user.email", "pctl@weave.works"},
+				{"add", "--all"},
+				{"commit", "-q", "-m", "initial"},
+			} {
+				out, err := exec.Command("git", append([]string{"-C", tmp}, args...)...).CombinedOutput()
+				Expect(err).NotTo(HaveOccurred(), string(out))
+			}
+			Expect(ioutil.WriteFile(filepath.Join(tmp, "weaveworks-nginx/profile.yaml"), []byte("v2"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(tmp, "weaveworks-nginx/Kustomization-2.yaml"), []byte("v2"), 0644)).To(Succeed())
+			Expect(os.Remove(filepath.Join(tmp, "weaveworks-nginx/HelmRelease-1.yaml"))).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(tmp, "unrelated.yaml"), []byte("v2"), 0644)).To(Succeed())
+
+			g := git.NewCLIGit(git.CLIGitConfig{
+				Paths:    []string{profileDir},
+				Location: tmp,
+			}, &pctlrunner.CLIRunner{})
+			Expect(g.Add()).To(Succeed())
+			Expect(g.Commit()).To(Succeed())
+			ok, err := g.HasChanges()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+
+			out, err := exec.Command("git", "-C", tmp, "show", "--name-status", "--format=", "HEAD").CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(out))
+			Expect(strings.Fields(string(out))).To(Equal([]string{
+				"D", "weaveworks-nginx/HelmRelease-1.yaml",
+				"A", "weaveworks-nginx/Kustomization-2.yaml",
+				"M", "weaveworks-nginx/profile.yaml",
+			}))
+			out, err = exec.Command("git", "-C", tmp, "status", "-s").CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(out))
+			Expect(string(out)).To(Equal(" M unrelated.yaml\n"))
+		})
+	})
+
+	Context("RemoteURL", func() {
+		It("returns the url of the remote", func() {
+			runner.RunReturns([]byte("git@github.com:weaveworks/pctl.git\n"), nil)
+			g := git.NewCLIGit(git.CLIGitConfig{
+				Location: "location",
+				Remote:   "origin",
+			}, runner)
+			url, err := g.RemoteURL()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url).To(Equal("git@github.com:weaveworks/pctl.git"))
+			arg, args := runner.RunArgsForCall(0)
+			Expect(arg).To(Equal("git"))
+			Expect(args).To(Equal([]string{"--git-dir", "location/.git", "--work-tree", "location", "remote", "get-url", "origin"}))
+		})
+		It("returns a sensible error if the remote doesn't exist", func() {
+			runner.RunReturns([]byte("error: No such remote 'origin'"), errors.New("nope"))
+			g := git.NewCLIGit(git.CLIGitConfig{
+				Location: "location",
+				Remote:   "origin",
+			}, runner)
+			_, err := g.RemoteURL()
+			Expect(err).To(MatchError("failed to get url of remote origin: nope"))
+		})
+	})
 })
@@ -0,0 +1,114 @@
+package git
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
+)
+
+// ProviderConfig defines which git provider the SCM client talks to and how it authenticates.
+type ProviderConfig struct {
+	// Provider is the go-scm driver name, for example github, gitlab or gitea.
+	Provider string
+	// Server is the URL of the provider. It can be left empty for github.com and gitlab.com.
+	Server string
+	// TokenFile is a file containing the token used to authenticate with the provider.
+	TokenFile string
+	// RemoteURL is the url of the git remote. It is used to detect the provider and server if they aren't set.
+	RemoteURL string
+}
+
+// NewSCMClient creates an scm client for the configured provider. Values which aren't configured are read from
+// the $GIT_KIND and $GIT_SERVER environment variables, and otherwise detected from the remote url.
+func NewSCMClient(cfg ProviderConfig) (*scm.Client, error) {
+	provider, server := cfg.Provider, cfg.Server
+	if provider == "" {
+		provider = os.Getenv("GIT_KIND")
+	}
+	if server == "" {
+		server = os.Getenv("GIT_SERVER")
+	}
+	if provider == "" && cfg.RemoteURL != "" {
+		detected, detectedServer, err := DetectProvider(cfg.RemoteURL)
+		if err != nil {
+			return nil, err
+		}
+		provider = detected
+		if server == "" {
+			server = detectedServer
+		}
+	}
+	token, err := readToken(cfg.TokenFile)
+	if err != nil {
+		return nil, err
+	}
+	client, err := factory.NewClient(provider, server, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for provider %q: %w", provider, err)
+	}
+	return client, nil
+}
+
+// DetectProvider returns the provider and the server url based on the host of a remote url. The server is empty
+// for github.com and gitlab.com. Both https and ssh remotes, including the scp-like git@host:org/repo syntax, are supported.
+func DetectProvider(remoteURL string) (string, string, error) {
+	host, err := remoteHost(remoteURL)
+	if err != nil {
+		return "", "", err
+	}
+	switch {
+	case host == "github.com":
+		return "github", "", nil
+	case host == "gitlab.com":
+		return "gitlab", "", nil
+	case strings.Contains(host, "github"):
+		return "github", "https://" + host, nil
+	case strings.Contains(host, "gitlab"):
+		return "gitlab", "https://" + host, nil
+	case strings.Contains(host, "gitea"):
+		return "gitea", "https://" + host, nil
+	}
+	return "", "", fmt.Errorf("unable to detect git provider from remote url %s, please set the provider explicitly", remoteURL)
+}
+
+// remoteHost returns the host name of a remote url.
+func remoteHost(remoteURL string) (string, error) {
+	if !strings.Contains(remoteURL, "://") {
+		// scp-like syntax: [user@]host:path
+		at := strings.Index(remoteURL, "@")
+		colon := strings.Index(remoteURL, ":")
+		if colon == -1 || colon < at {
+			return "", fmt.Errorf("failed to parse remote url %s", remoteURL)
+		}
+		return remoteURL[at+1 : colon], nil
+	}
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse remote url %s: %w", remoteURL, err)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("failed to parse remote url %s", remoteURL)
+	}
+	return u.Hostname(), nil
+}
+
+// readToken reads the token from the token file if it's given, otherwise from $GIT_TOKEN.
+func readToken(tokenFile string) (string, error) {
+	token := os.Getenv("GIT_TOKEN")
+	if tokenFile != "" {
+		content, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %w", err)
+		}
+		token = strings.TrimSpace(string(content))
+	}
+	if token == "" {
+		return "", fmt.Errorf("no git token provided, set $GIT_TOKEN or use a token file")
+	}
+	return token, nil
+}
@@ -0,0 +1,105 @@
+package git_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/go-scm/scm"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/weaveworks/pctl/pkg/git"
+)
+
+var _ = Describe("provider", func() {
+	Context("DetectProvider", func() {
+		for _, entry := range []struct {
+			name, remoteURL, provider, server string
+		}{
+			{"github https", "https://github.com/weaveworks/pctl.git", "github", ""},
+			{"github scp-like ssh", "git@github.com:weaveworks/pctl.git", "github", ""},
+			{"gitlab ssh", "ssh://git@gitlab.com/weaveworks/pctl.git", "gitlab", ""},
+			{"github enterprise", "https://github.example.com/weaveworks/pctl.git", "github", "https://github.example.com"},
+			{"self-hosted gitlab with a port", "ssh://git@gitlab.example.com:2222/weaveworks/pctl.git", "gitlab", "https://gitlab.example.com"},
+			{"gitea", "git@gitea.example.com:weaveworks/pctl.git", "gitea", "https://gitea.example.com"},
+		} {
+			entry := entry
+			It("detects the provider from a "+entry.name+" remote url", func() {
+				provider, server, err := git.DetectProvider(entry.remoteURL)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(provider).To(Equal(entry.provider))
+				Expect(server).To(Equal(entry.server))
+			})
+		}
+		It("returns an error if the provider can't be detected", func() {
+			_, _, err := git.DetectProvider("https://example.com/weaveworks/pctl.git")
+			Expect(err).To(MatchError("unable to detect git provider from remote url https://example.com/weaveworks/pctl.git, please set the provider explicitly"))
+		})
+		It("returns an error if the remote url can't be parsed", func() {
+			_, _, err := git.DetectProvider("not-a-remote")
+			Expect(err).To(MatchError("failed to parse remote url not-a-remote"))
+		})
+	})
+
+	Context("NewSCMClient", func() {
+		var (
+			tokenFile string
+			env       map[string]string
+		)
+
+		BeforeEach(func() {
+			env = map[string]string{}
+			for _, name := range []string{"GIT_KIND", "GIT_SERVER", "GIT_TOKEN"} {
+				if value, ok := os.LookupEnv(name); ok {
+					env[name] = value
+				}
+				Expect(os.Unsetenv(name)).To(Succeed())
+			}
+			dir, err := ioutil.TempDir("", "pctl-token")
+			Expect(err).NotTo(HaveOccurred())
+			tokenFile = filepath.Join(dir, "token")
+			Expect(ioutil.WriteFile(tokenFile, []byte("secret\n"), 0600)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			for name, value := range env {
+				_ = os.Setenv(name, value)
+			}
+			_ = os.RemoveAll(filepath.Dir(tokenFile))
+		})
+
+		It("uses the explicitly configured provider and server", func() {
+			client, err := git.NewSCMClient(git.ProviderConfig{
+				Provider:  "gitlab",
+				Server:    "https://gitlab.example.com",
+				TokenFile: tokenFile,
+				RemoteURL: "git@github.com:weaveworks/pctl.git",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.Driver).To(Equal(scm.DriverGitlab))
+			Expect(client.BaseURL.String()).To(Equal("https://gitlab.example.com/"))
+		})
+		It("detects the provider from the remote url", func() {
+			client, err := git.NewSCMClient(git.ProviderConfig{
+				TokenFile: tokenFile,
+				RemoteURL: "git@github.example.com:weaveworks/pctl.git",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.Driver).To(Equal(scm.DriverGithub))
+			Expect(client.BaseURL.String()).To(Equal("https://github.example.com/api/v3/"))
+		})
+		It("returns an error if no token is provided", func() {
+			_, err := git.NewSCMClient(git.ProviderConfig{
+				RemoteURL: "git@github.com:weaveworks/pctl.git",
+			})
+			Expect(err).To(MatchError("no git token provided, set $GIT_TOKEN or use a token file"))
+		})
+		It("returns an error if the token file can't be read", func() {
+			_, err := git.NewSCMClient(git.ProviderConfig{
+				TokenFile: "/does/not/exist",
+			})
+			Expect(err).To(MatchError(ContainSubstring("failed to read token file:")))
+		})
+	})
+})
@@ -1,25 +1,47 @@
 package git
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
-	"github.com/jenkins-x/go-scm/scm/factory"
 )
 
 // SCMClient defines the ability to create a pull request on a remote repository.
 //go:generate counterfeiter -o fakes/fake_scm.go . SCMClient
 type SCMClient interface {
-	CreatePullRequest() error
+	// CreatePullRequest creates a pull request, or updates an already open one, and returns its number.
+	CreatePullRequest() (int, error)
+	// AddLabels adds the configured labels to a pull request.
+	AddLabels(number int) error
+	// RequestReviews requests a review from the configured reviewers on a pull request.
+	RequestReviews(number int) error
+	// AddAssignees assigns the configured users to a pull request.
+	AddAssignees(number int) error
 }
 
+// DefaultPullRequestTitle is used if no pull request title is configured.
+const DefaultPullRequestTitle = "PCTL Generated Profile Resource Update"
+
 // SCMConfig defines configuration for the SCM Client that is needed to create a pull request.
 type SCMConfig struct {
-	Branch string
-	Base   string
-	Repo   string
-	Client *scm.Client
+	Branch    string
+	Base      string
+	Repo      string
+	Title     string
+	Body      string
+	Labels    []string
+	Reviewers []string
+	Assignees []string
+	Draft     bool
+	// Provider is used to create the scm client if Client isn't set.
+	Provider ProviderConfig
+	Client   *scm.Client
 }
 
 // Client defines a client which uses a real implementation to create pull requests.
@@ -30,29 +52,196 @@ type Client struct {
 // NewClient returns a real client.
 func NewClient(cfg SCMConfig) (*Client, error) {
 	if cfg.Client == nil {
-		c, err := factory.NewClientFromEnvironment()
+		c, err := NewSCMClient(cfg.Provider)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create scm client: %w", err)
 		}
 		cfg.Client = c
 	}
+	if cfg.Title == "" {
+		cfg.Title = DefaultPullRequestTitle
+	}
 	return &Client{
 		SCMConfig: cfg,
 	}, nil
 }
 
-// CreatePullRequest will create a pull request.
-func (r *Client) CreatePullRequest() error {
+// CreatePullRequest will create a pull request, or update the title and body of an already open
+// pull request for the same head and base.
+func (r *Client) CreatePullRequest() (int, error) {
 	fmt.Println("Creating pull request with : ", r.Repo, r.Base, r.Branch)
 	ctx := context.Background()
-	request, _, err := r.Client.PullRequests.Create(ctx, r.Repo, &scm.PullRequestInput{
-		Title: "PCTL Generated Profile Resource Update",
+	existing, err := r.findOpenPullRequest(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error while looking for existing pr: %w", err)
+	}
+	input := &scm.PullRequestInput{
+		Title: r.Title,
+		Body:  r.Body,
 		Head:  r.Branch,
 		Base:  r.Base,
-	})
+	}
+	if r.Draft {
+		if err := r.markDraft(input); err != nil {
+			return 0, err
+		}
+	}
+	if existing != nil {
+		request, _, err := r.Client.PullRequests.Update(ctx, r.Repo, existing.Number, input)
+		if err != nil {
+			return 0, fmt.Errorf("error while updating pr: %w", err)
+		}
+		fmt.Printf("PR updated with number: %d and URL: %s\n", request.Number, request.Link)
+		return request.Number, nil
+	}
+	var request *scm.PullRequest
+	if r.Draft && r.Client.Driver == scm.DriverGithub {
+		request, err = r.createGithubDraft(ctx, input)
+	} else {
+		request, _, err = r.Client.PullRequests.Create(ctx, r.Repo, input)
+	}
 	if err != nil {
-		return fmt.Errorf("error while creating pr: %w", err)
+		return 0, fmt.Errorf("error while creating pr: %w", err)
 	}
 	fmt.Printf("PR created with number: %d and URL: %s\n", request.Number, request.Link)
+	return request.Number, nil
+}
+
+// AddLabels adds the configured labels to a pull request.
+func (r *Client) AddLabels(number int) error {
+	for _, label := range r.Labels {
+		if _, err := r.Client.PullRequests.AddLabel(context.Background(), r.Repo, number, label); err != nil {
+			return fmt.Errorf("error while adding label %s: %w", label, err)
+		}
+	}
+	return nil
+}
+
+// RequestReviews requests a review from the configured reviewers on a pull request.
+func (r *Client) RequestReviews(number int) error {
+	if len(r.Reviewers) == 0 {
+		return nil
+	}
+	if _, err := r.Client.PullRequests.RequestReview(context.Background(), r.Repo, number, r.Reviewers); err != nil {
+		return fmt.Errorf("error while requesting reviews: %w", err)
+	}
 	return nil
 }
+
+// AddAssignees assigns the configured users to a pull request.
+func (r *Client) AddAssignees(number int) error {
+	if len(r.Assignees) == 0 {
+		return nil
+	}
+	if _, err := r.Client.PullRequests.AssignIssue(context.Background(), r.Repo, number, r.Assignees); err != nil {
+		return fmt.Errorf("error while adding assignees: %w", err)
+	}
+	return nil
+}
+
+// markDraft marks the pull request as a draft for providers which do that through the title. GitHub
+// drafts are created through the API instead, see createGithubDraft.
+func (r *Client) markDraft(input *scm.PullRequestInput) error {
+	switch r.Client.Driver {
+	case scm.DriverGithub:
+		return nil
+	case scm.DriverGitlab:
+		input.Title = addTitlePrefix(input.Title, "Draft: ")
+	case scm.DriverGitea:
+		input.Title = addTitlePrefix(input.Title, "WIP: ")
+	default:
+		return fmt.Errorf("draft pull requests are not supported for %s", r.Client.Driver)
+	}
+	return nil
+}
+
+func addTitlePrefix(title, prefix string) string {
+	if strings.HasPrefix(title, prefix) {
+		return title
+	}
+	return prefix + title
+}
+
+// githubPullRequest contains the fields of a GitHub pull request which pctl cares about.
+type githubPullRequest struct {
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	Head    string `json:"head"`
+	Base    string `json:"base"`
+	Draft   bool   `json:"draft"`
+	Number  int    `json:"number,omitempty"`
+	HTMLURL string `json:"html_url,omitempty"`
+}
+
+// createGithubDraft creates a draft pull request on GitHub. The go-scm pull request input has no
+// draft field, so the request is sent directly.
+func (r *Client) createGithubDraft(ctx context.Context, input *scm.PullRequestInput) (*scm.PullRequest, error) {
+	body, err := json.Marshal(githubPullRequest{
+		Title: input.Title,
+		Body:  input.Body,
+		Head:  input.Head,
+		Base:  input.Base,
+		Draft: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.Client.Do(ctx, &scm.Request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("repos/%s/pulls", r.Repo),
+		Header: http.Header{
+			"Content-Type": []string{"application/json"},
+			"Accept":       []string{"application/vnd.github.v3+json"},
+		},
+		Body: bytes.NewReader(body),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func(body io.ReadCloser) {
+		if err := body.Close(); err != nil {
+			fmt.Println("Failed to close body reader.")
+		}
+	}(resp.Body)
+	if resp.Status != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status code %d", resp.Status)
+	}
+	var out githubPullRequest
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &scm.PullRequest{
+		Number: out.Number,
+		Title:  out.Title,
+		Body:   out.Body,
+		Draft:  true,
+		Link:   out.HTMLURL,
+	}, nil
+}
+
+// findOpenPullRequest returns the open pull request from the configured branch into base, or nil if there is none.
+func (r *Client) findOpenPullRequest(ctx context.Context) (*scm.PullRequest, error) {
+	opts := scm.PullRequestListOptions{
+		Open: true,
+		Page: 1,
+		Size: 100,
+	}
+	for {
+		requests, resp, err := r.Client.PullRequests.List(ctx, r.Repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, pr := range requests {
+			if pr.Closed || pr.Merged {
+				continue
+			}
+			if pr.Head.Ref == r.Branch && pr.Base.Ref == r.Base {
+				return pr, nil
+			}
+		}
+		if resp == nil || resp.Page.Next == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.Page.Next
+	}
+}
@@ -1,6 +1,13 @@
 package git_test
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
 	"github.com/jenkins-x/go-scm/scm/factory"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -8,6 +15,23 @@ import (
 	"github.com/weaveworks/pctl/pkg/git"
 )
 
+// updatingPullRequestService adds Update to the fake driver, which doesn't implement it.
+type updatingPullRequestService struct {
+	scm.PullRequestService
+	updated []int
+}
+
+func (s *updatingPullRequestService) Update(ctx context.Context, repo string, number int, input *scm.PullRequestInput) (*scm.PullRequest, *scm.Response, error) {
+	pr, _, err := s.Find(ctx, repo, number)
+	if err != nil {
+		return nil, nil, err
+	}
+	pr.Title = input.Title
+	pr.Body = input.Body
+	s.updated = append(s.updated, number)
+	return pr, nil, nil
+}
+
 var _ = Describe("scm", func() {
 	When("we are trying to create a pull request", func() {
 		It("can use an scm client to talk to the platform", func() {
@@ -20,8 +44,99 @@ var _ = Describe("scm", func() {
 				Client: fakeScm,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			err = client.CreatePullRequest()
+			_, err = client.CreatePullRequest()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("uses the configured title and body", func() {
+			fakeScm, data := fake.NewDefault()
+			client, err := git.NewClient(git.SCMConfig{
+				Branch: "test01",
+				Base:   "main",
+				Repo:   "weaveworks/pctl-test-repo",
+				Title:  "Install nginx",
+				Body:   "Installs the nginx profile.",
+				Client: fakeScm,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.CreatePullRequest()
 			Expect(err).NotTo(HaveOccurred())
+			Expect(data.PullRequestsCreated).To(HaveKey(1))
+			Expect(data.PullRequestsCreated[1].Title).To(Equal("Install nginx"))
+			Expect(data.PullRequestsCreated[1].Body).To(Equal("Installs the nginx profile."))
+		})
+		It("falls back to the default title", func() {
+			fakeScm, data := fake.NewDefault()
+			client, err := git.NewClient(git.SCMConfig{
+				Branch: "test01",
+				Base:   "main",
+				Repo:   "weaveworks/pctl-test-repo",
+				Client: fakeScm,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.CreatePullRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.PullRequestsCreated[1].Title).To(Equal(git.DefaultPullRequestTitle))
+		})
+		It("updates an already open pull request instead of creating a new one", func() {
+			fakeScm, data := fake.NewDefault()
+			prs := &updatingPullRequestService{PullRequestService: fakeScm.PullRequests}
+			fakeScm.PullRequests = prs
+			cfg := git.SCMConfig{
+				Branch: "test01",
+				Base:   "main",
+				Repo:   "weaveworks/pctl-test-repo",
+				Title:  "Install nginx v0.0.1",
+				Client: fakeScm,
+			}
+			client, err := git.NewClient(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.CreatePullRequest()
+			Expect(err).NotTo(HaveOccurred())
+
+			cfg.Title = "Install nginx v0.0.2"
+			client, err = git.NewClient(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.CreatePullRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.PullRequestsCreated).To(HaveLen(1))
+			Expect(prs.updated).To(ConsistOf(1))
+			Expect(data.PullRequests[1].Title).To(Equal("Install nginx v0.0.2"))
+		})
+		It("creates a new pull request if the open one is for a different branch", func() {
+			fakeScm, data := fake.NewDefault()
+			prs := &updatingPullRequestService{PullRequestService: fakeScm.PullRequests}
+			fakeScm.PullRequests = prs
+			cfg := git.SCMConfig{
+				Branch: "test01",
+				Base:   "main",
+				Repo:   "weaveworks/pctl-test-repo",
+				Client: fakeScm,
+			}
+			client, err := git.NewClient(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.CreatePullRequest()
+			Expect(err).NotTo(HaveOccurred())
+
+			cfg.Branch = "test02"
+			client, err = git.NewClient(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.CreatePullRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.PullRequestsCreated).To(HaveLen(2))
+			Expect(prs.updated).To(BeEmpty())
+		})
+		It("returns the number of the created pull request", func() {
+			fakeScm, _ := fake.NewDefault()
+			client, err := git.NewClient(git.SCMConfig{
+				Branch: "test01",
+				Base:   "main",
+				Repo:   "weaveworks/pctl-test-repo",
+				Client: fakeScm,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			number, err := client.CreatePullRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(number).To(Equal(1))
 		})
 		It("fails if the scm client can't contact the provider", func() {
 			fakeScm, err := factory.NewClient("github", "https://invalid.url.com.here", "")
@@ -33,9 +148,9 @@ var _ = Describe("scm", func() {
 				Client: fakeScm,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			err = client.CreatePullRequest()
+			_, err = client.CreatePullRequest()
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(Equal("error while creating pr: Post \"https://invalid.url.com.here/api/v3/repos//pulls\": dial tcp: lookup invalid.url.com.here: no such host"))
+			Expect(err.Error()).To(Equal("error while looking for existing pr: Get \"https://invalid.url.com.here/api/v3/repos//pulls?page=1&per_page=100\": dial tcp: lookup invalid.url.com.here: no such host"))
 		})
 		It("fails if token is invalid", func() {
 			fakeScm, err := factory.NewClient("github", "https://api.github.com", "invalid")
@@ -47,9 +162,9 @@ var _ = Describe("scm", func() {
 				Client: fakeScm,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			err = client.CreatePullRequest()
+			_, err = client.CreatePullRequest()
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(Equal("error while creating pr: Unauthorized"))
+			Expect(err.Error()).To(Equal("error while looking for existing pr: Unauthorized"))
 		})
 		It("fails if token is not provided", func() {
 			fakeScm, err := factory.NewClient("github", "https://api.github.com", "")
@@ -61,9 +176,122 @@ var _ = Describe("scm", func() {
 				Client: fakeScm,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			err = client.CreatePullRequest()
+			_, err = client.CreatePullRequest()
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(Equal("error while creating pr: Not Found"))
 		})
 	})
+
+	When("labels, reviewers and assignees are configured", func() {
+		var (
+			fakeScm *scm.Client
+			data    *fake.Data
+			client  *git.Client
+		)
+
+		BeforeEach(func() {
+			fakeScm, data = fake.NewDefault()
+			var err error
+			client, err = git.NewClient(git.SCMConfig{
+				Branch:    "test01",
+				Base:      "main",
+				Repo:      "weaveworks/pctl-test-repo",
+				Labels:    []string{"profiles", "needs-review"},
+				Reviewers: []string{"alice"},
+				Assignees: []string{"bob", "carol"},
+				Client:    fakeScm,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.CreatePullRequest()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("adds the labels to the pull request", func() {
+			Expect(client.AddLabels(1)).To(Succeed())
+			Expect(data.PullRequestLabelsAdded).To(ConsistOf(
+				"weaveworks/pctl-test-repo#1:profiles",
+				"weaveworks/pctl-test-repo#1:needs-review",
+			))
+		})
+
+		It("assigns the users to the pull request", func() {
+			Expect(client.AddAssignees(1)).To(Succeed())
+			Expect(data.AssigneesAdded).To(ConsistOf(
+				"weaveworks/pctl-test-repo#1:bob",
+				"weaveworks/pctl-test-repo#1:carol",
+			))
+		})
+
+		It("returns a sensible error if the provider can't request reviews", func() {
+			err := client.RequestReviews(1)
+			Expect(err).To(MatchError("error while requesting reviews: Not Supported"))
+		})
+	})
+
+	When("nothing is configured", func() {
+		It("doesn't call the provider", func() {
+			fakeScm, data := fake.NewDefault()
+			client, err := git.NewClient(git.SCMConfig{
+				Repo:   "weaveworks/pctl-test-repo",
+				Client: fakeScm,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.AddLabels(1)).To(Succeed())
+			Expect(client.RequestReviews(1)).To(Succeed())
+			Expect(client.AddAssignees(1)).To(Succeed())
+			Expect(data.PullRequestLabelsAdded).To(BeEmpty())
+			Expect(data.AssigneesAdded).To(BeEmpty())
+		})
+	})
+
+	When("a draft is requested", func() {
+		It("creates a draft pull request on GitHub", func() {
+			var created map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					_, _ = w.Write([]byte(`[]`))
+				case http.MethodPost:
+					Expect(r.URL.Path).To(Equal("/api/v3/repos/weaveworks/pctl-test-repo/pulls"))
+					Expect(json.NewDecoder(r.Body).Decode(&created)).To(Succeed())
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"number": 42, "html_url": "https://github.com/weaveworks/pctl-test-repo/pull/42"}`))
+				}
+			}))
+			defer server.Close()
+			githubClient, err := factory.NewClient("github", server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+			client, err := git.NewClient(git.SCMConfig{
+				Branch: "test01",
+				Base:   "main",
+				Repo:   "weaveworks/pctl-test-repo",
+				Title:  "Install nginx",
+				Draft:  true,
+				Client: githubClient,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			number, err := client.CreatePullRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(number).To(Equal(42))
+			Expect(created).To(Equal(map[string]interface{}{
+				"title": "Install nginx",
+				"body":  "",
+				"head":  "test01",
+				"base":  "main",
+				"draft": true,
+			}))
+		})
+
+		It("fails for providers which don't support drafts", func() {
+			fakeScm, _ := fake.NewDefault()
+			client, err := git.NewClient(git.SCMConfig{
+				Repo:   "weaveworks/pctl-test-repo",
+				Draft:  true,
+				Client: fakeScm,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.CreatePullRequest()
+			Expect(err).To(MatchError("draft pull requests are not supported for fake"))
+		})
+	})
 })
@@ -0,0 +1,78 @@
+package subscription
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DeleteOptions configures the deletion of a subscription
+type DeleteOptions struct {
+	// Orphan keeps the resources created for the subscription instead of deleting them with it.
+	Orphan bool
+	// Wait waits until the subscription and the resources deleted with it are gone.
+	Wait     bool
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// Delete deletes a subscription. The resources created for it are deleted by the garbage collector, apart from
+// ones without an owner reference, which are deleted explicitly. It returns the deleted resources.
+func (sm *Manager) Delete(namespace, name string, opts DeleteOptions) ([]string, error) {
+	sub, err := sm.getSubscription(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	var children []client.Object
+	if !opts.Orphan {
+		if children, err = sm.children(sub); err != nil {
+			return nil, err
+		}
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	if opts.Orphan {
+		propagation = metav1.DeletePropagationOrphan
+	}
+	if err := sm.kClient.Delete(sm.ctx, sub, client.PropagationPolicy(propagation)); err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to delete profile subscription: %w", err)
+	}
+	deleted := []string{"ProfileSubscription/" + sub.Name}
+	for _, child := range children {
+		if metav1.GetControllerOf(child) == nil {
+			if err := sm.kClient.Delete(sm.ctx, child); err != nil && !apierrors.IsNotFound(err) {
+				return deleted, fmt.Errorf("failed to delete %s/%s: %w", kindOf(child), child.GetName(), err)
+			}
+		}
+		deleted = append(deleted, kindOf(child)+"/"+child.GetName())
+	}
+	if !opts.Wait {
+		return deleted, nil
+	}
+
+	objects := append([]client.Object{sub}, children...)
+	var remaining []string
+	err = wait.PollImmediate(opts.Interval, opts.Timeout, func() (bool, error) {
+		remaining = nil
+		for _, obj := range objects {
+			err := sm.kClient.Get(sm.ctx, client.ObjectKeyFromObject(obj), obj.DeepCopyObject().(client.Object))
+			switch {
+			case apierrors.IsNotFound(err):
+			case err != nil:
+				return false, fmt.Errorf("failed to get %s: %w", obj.GetName(), err)
+			default:
+				remaining = append(remaining, kindOf(obj)+"/"+obj.GetName())
+			}
+		}
+		return len(remaining) == 0, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return deleted, fmt.Errorf("timed out waiting for deletion of %s", strings.Join(remaining, ", "))
+	}
+	return deleted, err
+}
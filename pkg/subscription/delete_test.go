@@ -0,0 +1,114 @@
+package subscription_test
+
+import (
+	"context"
+	"time"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/pctl/pkg/subscription"
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Delete", func() {
+	var (
+		sm         *subscription.Manager
+		fakeClient client.Client
+		sub1       = "sub1"
+		namespace1 = "namespace1"
+		controller = true
+		exists     = func(name string, obj client.Object) bool {
+			err := fakeClient.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: namespace1}, obj)
+			if apierrors.IsNotFound(err) {
+				return false
+			}
+			Expect(err).NotTo(HaveOccurred())
+			return true
+		}
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(profilesv1.AddToScheme(scheme)).To(Succeed())
+		Expect(sourcev1.AddToScheme(scheme)).To(Succeed())
+		Expect(helmv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kustomizev1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&profilesv1.ProfileSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: sub1, Namespace: namespace1, UID: "sub1-uid"},
+				Spec:       profilesv1.ProfileSubscriptionSpec{ProfileURL: "https://github.com/org/repo", Branch: "main", Path: "nginx"},
+			},
+			&sourcev1.GitRepository{ObjectMeta: metav1.ObjectMeta{Name: "sub1-repo-main", Namespace: namespace1}},
+			&helmv2.HelmRelease{ObjectMeta: metav1.ObjectMeta{Name: "sub1-nginx-server", Namespace: namespace1}},
+		).Build()
+		sm = subscription.NewManager(fakeClient)
+	})
+
+	It("deletes the subscription and the resources created for it", func() {
+		deleted, err := sm.Delete(namespace1, sub1, subscription.DeleteOptions{Wait: true, Interval: time.Millisecond, Timeout: time.Second})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(Equal([]string{"ProfileSubscription/sub1", "GitRepository/sub1-repo-main", "HelmRelease/sub1-nginx-server"}))
+		Expect(exists(sub1, &profilesv1.ProfileSubscription{})).To(BeFalse())
+		Expect(exists("sub1-repo-main", &sourcev1.GitRepository{})).To(BeFalse())
+		Expect(exists("sub1-nginx-server", &helmv2.HelmRelease{})).To(BeFalse())
+	})
+
+	When("cascade is orphan", func() {
+		It("only deletes the subscription", func() {
+			deleted, err := sm.Delete(namespace1, sub1, subscription.DeleteOptions{Orphan: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(Equal([]string{"ProfileSubscription/sub1"}))
+			Expect(exists(sub1, &profilesv1.ProfileSubscription{})).To(BeFalse())
+			Expect(exists("sub1-repo-main", &sourcev1.GitRepository{})).To(BeTrue())
+			Expect(exists("sub1-nginx-server", &helmv2.HelmRelease{})).To(BeTrue())
+		})
+	})
+
+	When("the name of another subscription starts with the name of the subscription", func() {
+		BeforeEach(func() {
+			Expect(fakeClient.Create(context.TODO(), &profilesv1.ProfileSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "sub1-staging", Namespace: namespace1},
+				Spec:       profilesv1.ProfileSubscriptionSpec{ProfileURL: "https://github.com/org/repo", Branch: "main", Path: "nginx"},
+			})).To(Succeed())
+			Expect(fakeClient.Create(context.TODO(), &sourcev1.GitRepository{ObjectMeta: metav1.ObjectMeta{Name: "sub1-staging-repo-main", Namespace: namespace1}})).To(Succeed())
+			Expect(fakeClient.Create(context.TODO(), &helmv2.HelmRelease{ObjectMeta: metav1.ObjectMeta{Name: "sub1-staging-nginx-server", Namespace: namespace1}})).To(Succeed())
+		})
+
+		It("keeps the resources of the other subscription", func() {
+			deleted, err := sm.Delete(namespace1, sub1, subscription.DeleteOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(Equal([]string{"ProfileSubscription/sub1", "GitRepository/sub1-repo-main", "HelmRelease/sub1-nginx-server"}))
+			Expect(exists("sub1-staging", &profilesv1.ProfileSubscription{})).To(BeTrue())
+			Expect(exists("sub1-staging-repo-main", &sourcev1.GitRepository{})).To(BeTrue())
+			Expect(exists("sub1-staging-nginx-server", &helmv2.HelmRelease{})).To(BeTrue())
+		})
+	})
+
+	When("a resource isn't deleted in time", func() {
+		BeforeEach(func() {
+			// owned resources are deleted by the garbage collector, which the fake client doesn't have
+			Expect(fakeClient.Create(context.TODO(), &kustomizev1.Kustomization{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "sub1-nginx-deployment",
+					Namespace: namespace1,
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: "weave.works/v1alpha1", Kind: "ProfileSubscription", Name: sub1, UID: "sub1-uid", Controller: &controller},
+					},
+				},
+			})).To(Succeed())
+		})
+
+		It("returns the remaining resources", func() {
+			_, err := sm.Delete(namespace1, sub1, subscription.DeleteOptions{Wait: true, Interval: time.Millisecond, Timeout: 10 * time.Millisecond})
+			Expect(err).To(MatchError("timed out waiting for deletion of Kustomization/sub1-nginx-deployment"))
+		})
+	})
+})
@@ -0,0 +1,76 @@
+package subscription
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EventSummary contains a summary of an event of a subscription or one of its resources
+type EventSummary struct {
+	Kind     string    `json:"kind"`
+	Name     string    `json:"name"`
+	Type     string    `json:"type"`
+	Reason   string    `json:"reason"`
+	Message  string    `json:"message"`
+	Count    int32     `json:"count"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// Events returns the events of a subscription and of the flux resources it created, oldest first
+func (sm *Manager) Events(namespace, name string) ([]EventSummary, error) {
+	sub, err := sm.getSubscription(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	children, err := sm.children(sub)
+	if err != nil {
+		return nil, err
+	}
+	involved := map[string]bool{involvedKey("ProfileSubscription", sub.Name): true}
+	for _, child := range children {
+		involved[involvedKey(kindOf(child), child.GetName())] = true
+	}
+
+	var events corev1.EventList
+	if err := sm.kClient.List(sm.ctx, &events, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list events of subscription: %w", err)
+	}
+	var summaries []EventSummary
+	for _, e := range events.Items {
+		if !involved[involvedKey(e.InvolvedObject.Kind, e.InvolvedObject.Name)] {
+			continue
+		}
+		summaries = append(summaries, EventSummary{
+			Kind:     e.InvolvedObject.Kind,
+			Name:     e.InvolvedObject.Name,
+			Type:     e.Type,
+			Reason:   e.Reason,
+			Message:  e.Message,
+			Count:    e.Count,
+			LastSeen: lastSeen(e),
+		})
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].LastSeen.Before(summaries[j].LastSeen)
+	})
+	return summaries, nil
+}
+
+func involvedKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// lastSeen returns when an event last occurred. Events recorded with the events API only set EventTime.
+func lastSeen(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	}
+	return e.FirstTimestamp.Time
+}
@@ -0,0 +1,75 @@
+package subscription_test
+
+import (
+	"time"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/pctl/pkg/subscription"
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Events", func() {
+	var (
+		sm         *subscription.Manager
+		sub1       = "sub1"
+		namespace1 = "namespace1"
+		now        = time.Date(2021, 5, 20, 12, 0, 0, 0, time.UTC)
+		event      = func(name, kind, involved string, lastSeen time.Time) *corev1.Event {
+			return &corev1.Event{
+				ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: namespace1},
+				InvolvedObject: corev1.ObjectReference{Kind: kind, Name: involved, Namespace: namespace1},
+				Type:           corev1.EventTypeWarning,
+				Reason:         "Failed",
+				Message:        name,
+				Count:          1,
+				LastTimestamp:  metav1.NewTime(lastSeen),
+			}
+		}
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(profilesv1.AddToScheme(scheme)).To(Succeed())
+		Expect(sourcev1.AddToScheme(scheme)).To(Succeed())
+		Expect(helmv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kustomizev1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&profilesv1.ProfileSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: sub1, Namespace: namespace1},
+				Spec:       profilesv1.ProfileSubscriptionSpec{ProfileURL: "https://github.com/org/repo", Branch: "main", Path: "nginx"},
+			},
+			&helmv2.HelmRelease{ObjectMeta: metav1.ObjectMeta{Name: "sub1-nginx-server", Namespace: namespace1}},
+			event("release-failed", "HelmRelease", "sub1-nginx-server", now.Add(-time.Minute)),
+			event("reconcile-failed", "ProfileSubscription", sub1, now),
+			event("other-sub", "ProfileSubscription", "sub2", now),
+			// a kind the subscription didn't create with the same name
+			event("other-kind", "Kustomization", "sub1-nginx-server", now),
+		).Build()
+		sm = subscription.NewManager(fakeClient)
+	})
+
+	It("returns the events of the subscription and its resources, oldest first", func() {
+		events, err := sm.Events(namespace1, sub1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(Equal([]subscription.EventSummary{
+			{Kind: "HelmRelease", Name: "sub1-nginx-server", Type: "Warning", Reason: "Failed", Message: "release-failed", Count: 1, LastSeen: now.Add(-time.Minute).Local()},
+			{Kind: "ProfileSubscription", Name: sub1, Type: "Warning", Reason: "Failed", Message: "reconcile-failed", Count: 1, LastSeen: now.Local()},
+		}))
+	})
+
+	When("the subscription doesn't exist", func() {
+		It("returns an error", func() {
+			_, err := sm.Events(namespace1, "sub3")
+			Expect(err).To(MatchError(ContainSubstring("failed to get profile subscriptions:")))
+		})
+	})
+})
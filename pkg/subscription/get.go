@@ -2,42 +2,81 @@ package subscription
 
 import (
 	"fmt"
+	"path"
+	"strings"
+	"time"
 
 	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // SubscriptionSummary contains a summary of a subscription
 type SubscriptionSummary struct {
-	Name      string
-	Namespace string
-	Ready     string
-	Message   string
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Ready      string `json:"ready"`
+	Message    string `json:"message"`
+	Profile    string `json:"profile"`
+	ProfileURL string `json:"url"`
+	// Version is the version of the profile, or the branch it's installed from
+	Version string    `json:"version"`
+	Created time.Time `json:"created"`
 }
 
 // Get returns a SubscriptionSummary for a given subscription
 func (sm *Manager) Get(namespace, name string) (SubscriptionSummary, error) {
 	var sub profilesv1.ProfileSubscription
-	var summary SubscriptionSummary
 	err := sm.kClient.Get(sm.ctx, client.ObjectKey{Name: name, Namespace: namespace}, &sub)
 	if err != nil {
 		return SubscriptionSummary{}, fmt.Errorf("failed to get profile subscriptions: %w", err)
 	}
-	status := "Unknown"
-	message := "-"
-	for _, cond := range sub.Status.Conditions {
-		if cond.Type == "Ready" {
-			status = string(cond.Status)
-			message = cond.Message
-			break
+	return newSummary(sub), nil
+}
+
+// newSummary summarizes a subscription, its readiness is the status of its Ready condition
+func newSummary(sub profilesv1.ProfileSubscription) SubscriptionSummary {
+	ready, message := readyCondition(sub.Status.Conditions)
+	profile, version := profileVersion(sub.Spec)
+	return SubscriptionSummary{
+		Name:       sub.Name,
+		Namespace:  sub.Namespace,
+		Ready:      ready,
+		Message:    message,
+		Profile:    profile,
+		ProfileURL: sub.Spec.ProfileURL,
+		Version:    version,
+		Created:    sub.CreationTimestamp.Time,
+	}
+}
+
+// profileVersion returns the name and version of the profile of a subscription. Subscriptions of a version
+// have it set to <profile-path>/<tag>, those of a branch may set the path to the profile. Profiles at the root
+// of their repository are named after it.
+func profileVersion(spec profilesv1.ProfileSubscriptionSpec) (string, string) {
+	profilePath, version := spec.Path, spec.Branch
+	if spec.Version != "" {
+		i := strings.LastIndex(spec.Version, "/")
+		version = spec.Version[i+1:]
+		if profilePath == "" && i != -1 {
+			profilePath = spec.Version[:i]
 		}
 	}
+	if profilePath == "" || profilePath == "." {
+		profilePath = strings.TrimSuffix(spec.ProfileURL, ".git")
+	}
+	if profilePath == "" {
+		return "", version
+	}
+	return path.Base(profilePath), version
+}
 
-	summary = SubscriptionSummary{
-		Name:      sub.Name,
-		Namespace: sub.Namespace,
-		Ready:     status,
-		Message:   message,
+// readyCondition returns the status and message of the Ready condition.
+func readyCondition(conditions []metav1.Condition) (string, string) {
+	for _, cond := range conditions {
+		if cond.Type == "Ready" {
+			return string(cond.Status), cond.Message
+		}
 	}
-	return summary, nil
+	return "Unknown", "-"
 }
@@ -59,10 +59,13 @@ var _ = Describe("Get", func() {
 		sub, err := sm.Get(namespace1, sub1)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(sub).To(Equal(subscription.SubscriptionSummary{
-			Name:      sub1,
-			Namespace: namespace1,
-			Ready:     "True",
-			Message:   "foo",
+			Name:       sub1,
+			Namespace:  namespace1,
+			Ready:      "True",
+			Message:    "foo",
+			Profile:    "repo-name",
+			ProfileURL: "https://github.com/org/repo-name",
+			Version:    "main",
 		}))
 	})
 
@@ -93,10 +96,13 @@ var _ = Describe("Get", func() {
 			sub, err := sm.Get(namespace1, sub1)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(sub).To(Equal(subscription.SubscriptionSummary{
-				Name:      sub1,
-				Namespace: namespace1,
-				Ready:     "Unknown",
-				Message:   "-",
+				Name:       sub1,
+				Namespace:  namespace1,
+				Ready:      "Unknown",
+				Message:    "-",
+				Profile:    "repo-name",
+				ProfileURL: "https://github.com/org/repo-name",
+				Version:    "main",
 			}))
 		})
 	})
@@ -4,30 +4,86 @@ import (
 	"fmt"
 
 	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// ListOptions selects the subscriptions to list
+type ListOptions struct {
+	// Namespace to list subscriptions in. Subscriptions in all namespaces are listed if it's empty.
+	Namespace string
+	// LabelSelector selects subscriptions by their labels, such as "team=a,env!=prod".
+	LabelSelector string
+	// FieldSelector selects subscriptions by metadata.name and metadata.namespace, such as "metadata.name=my-sub".
+	FieldSelector string
+	// NotReady only selects subscriptions which aren't Ready.
+	NotReady bool
+	// Profile only selects subscriptions of the profile with this name.
+	Profile string
+}
+
 // List returns a list of subscriptions
-func (sm *Manager) List() ([]SubscriptionSummary, error) {
+func (sm *Manager) List(opts ListOptions) ([]SubscriptionSummary, error) {
+	sel, err := opts.selector()
+	if err != nil {
+		return nil, err
+	}
 	var subscriptions profilesv1.ProfileSubscriptionList
-	err := sm.kClient.List(sm.ctx, &subscriptions)
+	err = sm.kClient.List(sm.ctx, &subscriptions,
+		client.InNamespace(opts.Namespace),
+		client.MatchingLabelsSelector{Selector: sel.labels},
+		client.MatchingFieldsSelector{Selector: sel.fields},
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list profile subscriptions: %w", err)
 	}
 	var descriptions []SubscriptionSummary
 	for _, sub := range subscriptions.Items {
-		status := "Unknown"
-		for _, cond := range sub.Status.Conditions {
-			if cond.Type == "Ready" {
-				status = string(sub.Status.Conditions[0].Status)
-				break
-			}
+		if summary := newSummary(sub); sel.matches(sub, summary) {
+			descriptions = append(descriptions, summary)
 		}
-
-		descriptions = append(descriptions, SubscriptionSummary{
-			Name:      sub.Name,
-			Namespace: sub.Namespace,
-			Ready:     status,
-		})
 	}
 	return descriptions, nil
 }
+
+// selector is the parsed form of ListOptions.
+type selector struct {
+	labels   labels.Selector
+	fields   fields.Selector
+	notReady bool
+	profile  string
+}
+
+func (o ListOptions) selector() (*selector, error) {
+	labelSelector, err := labels.Parse(o.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector %q: %w", o.LabelSelector, err)
+	}
+	fieldSelector, err := fields.ParseSelector(o.FieldSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field selector %q: %w", o.FieldSelector, err)
+	}
+	return &selector{
+		labels:   labelSelector,
+		fields:   fieldSelector,
+		notReady: o.NotReady,
+		profile:  o.Profile,
+	}, nil
+}
+
+// matches returns whether a subscription is selected. The label and field selectors are checked as well,
+// even though the API server already applies them, so clients which don't support them get the same result.
+func (s *selector) matches(sub profilesv1.ProfileSubscription, summary SubscriptionSummary) bool {
+	if !s.labels.Matches(labels.Set(sub.Labels)) {
+		return false
+	}
+	if !s.fields.Matches(fields.Set{"metadata.name": sub.Name, "metadata.namespace": sub.Namespace}) {
+		return false
+	}
+	if s.notReady && summary.Ready == string(metav1.ConditionTrue) {
+		return false
+	}
+	return s.profile == "" || s.profile == summary.Profile
+}
@@ -25,8 +25,27 @@ var _ = Describe("List", func() {
 		sub2       = "sub2"
 		namespace1 = "namespace1"
 		namespace2 = "namespace2"
+		summary1   subscription.SubscriptionSummary
+		summary2   subscription.SubscriptionSummary
 	)
 	BeforeEach(func() {
+		summary1 = subscription.SubscriptionSummary{
+			Name:       sub1,
+			Namespace:  namespace1,
+			Ready:      "True",
+			Profile:    "repo-name",
+			ProfileURL: "https://github.com/org/repo-name",
+			Version:    "main",
+		}
+		summary2 = subscription.SubscriptionSummary{
+			Name:       sub2,
+			Namespace:  namespace2,
+			Ready:      "False",
+			Message:    "error when reconciling profile artifacts",
+			Profile:    "weaveworks-nginx",
+			ProfileURL: "https://github.com/org/repo-name",
+			Version:    "v0.1.0",
+		}
 		scheme := runtime.NewScheme()
 		Expect(profilesv1.AddToScheme(scheme)).To(Succeed())
 		fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
@@ -44,6 +63,11 @@ var _ = Describe("List", func() {
 		pSub2 := pSub1.DeepCopy()
 		pSub2.Name = sub2
 		pSub2.Namespace = namespace2
+		pSub2.Labels = map[string]string{"team": "a"}
+		pSub2.Spec = profilesv1.ProfileSubscriptionSpec{
+			ProfileURL: "https://github.com/org/repo-name",
+			Version:    "weaveworks-nginx/v0.1.0",
+		}
 		Expect(fakeClient.Create(context.TODO(), pSub1)).To(Succeed())
 		Expect(fakeClient.Create(context.TODO(), pSub2)).To(Succeed())
 		condition := metav1.Condition{
@@ -58,29 +82,73 @@ var _ = Describe("List", func() {
 		pSub1New.Status.Conditions = conditions
 		Expect(fakeClient.Status().Patch(context.TODO(), pSub1New, client.MergeFrom(pSub1))).To(Succeed())
 
-		conditions[0].Status = "False"
+		// the Ready condition isn't necessarily the first one
 		pSub2New := pSub2.DeepCopy()
-		pSub2New.Status.Conditions = conditions
+		pSub2New.Status.Conditions = []metav1.Condition{
+			{
+				Type:               "Reconciling",
+				Status:             "True",
+				Reason:             "foo",
+				LastTransitionTime: metav1.Now(),
+			},
+			{
+				Type:               "Ready",
+				Status:             "False",
+				Reason:             "foo",
+				Message:            "error when reconciling profile artifacts",
+				LastTransitionTime: metav1.Now(),
+			},
+		}
 		Expect(fakeClient.Status().Patch(context.TODO(), pSub2New, client.MergeFrom(pSub2))).To(Succeed())
 
 		sm = subscription.NewManager(fakeClient)
 	})
 
 	It("returns a list of profiles deployed in the cluster", func() {
-		subs, err := sm.List()
+		subs, err := sm.List(subscription.ListOptions{})
 		Expect(err).NotTo(HaveOccurred())
-		Expect(subs).To(ConsistOf(
-			subscription.SubscriptionSummary{
-				Name:      sub1,
-				Namespace: namespace1,
-				Ready:     "True",
-			},
-			subscription.SubscriptionSummary{
-				Name:      sub2,
-				Namespace: namespace2,
-				Ready:     "False",
-			},
-		))
+		Expect(subs).To(ConsistOf(summary1, summary2))
+	})
+
+	When("a namespace is given", func() {
+		It("only returns the profiles in that namespace", func() {
+			subs, err := sm.List(subscription.ListOptions{Namespace: namespace2})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subs).To(ConsistOf(summary2))
+		})
+	})
+
+	When("selectors are given", func() {
+		It("returns the profiles matching the label selector", func() {
+			subs, err := sm.List(subscription.ListOptions{LabelSelector: "team=a"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subs).To(ConsistOf(summary2))
+		})
+
+		It("returns the profiles matching the field selector", func() {
+			subs, err := sm.List(subscription.ListOptions{FieldSelector: "metadata.name=" + sub1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subs).To(ConsistOf(summary1))
+		})
+
+		It("returns an error for an invalid selector", func() {
+			_, err := sm.List(subscription.ListOptions{LabelSelector: "team in"})
+			Expect(err).To(MatchError(ContainSubstring(`invalid label selector "team in":`)))
+		})
+	})
+
+	When("filters are given", func() {
+		It("only returns the profiles which aren't ready", func() {
+			subs, err := sm.List(subscription.ListOptions{NotReady: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subs).To(ConsistOf(summary2))
+		})
+
+		It("only returns the profiles with the given name", func() {
+			subs, err := sm.List(subscription.ListOptions{Profile: "weaveworks-nginx"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subs).To(ConsistOf(summary2))
+		})
 	})
 
 	When("the list fails", func() {
@@ -92,7 +160,7 @@ var _ = Describe("List", func() {
 		})
 
 		It("returns an error", func() {
-			_, err := sm.List()
+			_, err := sm.List(subscription.ListOptions{})
 			Expect(err).To(MatchError(ContainSubstring("failed to list profile subscriptions:")))
 		})
 	})
@@ -108,25 +176,16 @@ var _ = Describe("List", func() {
 			pSub2 := &profilesv1.ProfileSubscription{}
 			Expect(fakeClient.Get(context.TODO(), client.ObjectKey{Name: sub2, Namespace: namespace2}, pSub2)).To(Succeed())
 			pSub2New := pSub2.DeepCopy()
-			pSub2New.Status.Conditions[0].Type = "not a ready status"
+			pSub2New.Status.Conditions[1].Type = "not a ready status"
 			Expect(fakeClient.Status().Patch(context.TODO(), pSub2New, client.MergeFrom(pSub2))).To(Succeed())
 		})
 
 		It("sets the status to unknown", func() {
-			subs, err := sm.List()
+			subs, err := sm.List(subscription.ListOptions{})
 			Expect(err).NotTo(HaveOccurred())
-			Expect(subs).To(ConsistOf(
-				subscription.SubscriptionSummary{
-					Name:      sub1,
-					Namespace: namespace1,
-					Ready:     "Unknown",
-				},
-				subscription.SubscriptionSummary{
-					Name:      sub2,
-					Namespace: namespace2,
-					Ready:     "Unknown",
-				},
-			))
+			summary1.Ready, summary1.Message = "Unknown", "-"
+			summary2.Ready, summary2.Message = "Unknown", "-"
+			Expect(subs).To(ConsistOf(summary1, summary2))
 
 		})
 	})
@@ -0,0 +1,132 @@
+package subscription
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileRequestAnnotation asks the flux controllers to reconcile a resource right away. Its value is recorded
+// as lastHandledReconcileAt in the status of the resource once it's reconciled.
+const reconcileRequestAnnotation = "reconcile.fluxcd.io/requestedAt"
+
+// Reconcile requests the reconciliation of a subscription and of the resources it created. It returns the value
+// of the request, which WaitForReconcile waits for.
+func (sm *Manager) Reconcile(namespace, name string) (string, error) {
+	sub, err := sm.getSubscription(namespace, name)
+	if err != nil {
+		return "", err
+	}
+	children, err := sm.children(sub)
+	if err != nil {
+		return "", err
+	}
+	requestedAt := time.Now().Format(time.RFC3339Nano)
+	for _, obj := range append([]client.Object{sub}, children...) {
+		patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[reconcileRequestAnnotation] = requestedAt
+		obj.SetAnnotations(annotations)
+		if err := sm.kClient.Patch(sm.ctx, obj, patch); err != nil {
+			return "", fmt.Errorf("failed to request reconciliation of %s: %w", obj.GetName(), err)
+		}
+	}
+	return requestedAt, nil
+}
+
+// WaitForReconcile waits until all resources of a subscription handled the reconcile request and returns the status
+// of the subscription and its resources as they were when they did. It returns an error if a resource isn't Ready after its reconciliation, or right away if one of them is
+// suspended.
+func (sm *Manager) WaitForReconcile(namespace, name, requestedAt string, interval, timeout time.Duration) (SubscriptionStatus, error) {
+	var (
+		sub      *profilesv1.ProfileSubscription
+		children []client.Object
+	)
+	err := wait.PollImmediate(interval, timeout, func() (bool, error) {
+		var err error
+		sub, err = sm.getSubscription(namespace, name)
+		if err != nil {
+			return false, err
+		}
+		if children, err = sm.children(sub); err != nil {
+			return false, err
+		}
+		// flux doesn't reconcile suspended resources, so they would never handle the request
+		var suspended []string
+		for _, child := range children {
+			if isSuspended(child) {
+				suspended = append(suspended, kindOf(child)+"/"+child.GetName())
+			}
+		}
+		if len(suspended) > 0 {
+			return false, fmt.Errorf("subscription is suspended: %s", strings.Join(suspended, ", "))
+		}
+		for _, child := range children {
+			if lastHandledReconcileAt(child) != requestedAt {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		var pending []string
+		for _, child := range children {
+			if lastHandledReconcileAt(child) != requestedAt {
+				pending = append(pending, kindOf(child)+"/"+child.GetName())
+			}
+		}
+		return SubscriptionStatus{}, fmt.Errorf("timed out waiting for reconciliation of %s", strings.Join(pending, ", "))
+	}
+	if err != nil {
+		return SubscriptionStatus{}, err
+	}
+
+	status := SubscriptionStatus{SubscriptionSummary: newSummary(*sub)}
+	var failed []string
+	for _, child := range children {
+		rs := resourceStatus(child)
+		if rs.Ready != string(metav1.ConditionTrue) {
+			failed = append(failed, fmt.Sprintf("%s/%s: %s", rs.Kind, rs.Name, rs.Message))
+		}
+		status.Resources = append(status.Resources, rs)
+	}
+	if len(failed) > 0 {
+		return status, fmt.Errorf("reconciliation failed: %s", strings.Join(failed, "; "))
+	}
+	return status, nil
+}
+
+func lastHandledReconcileAt(obj client.Object) string {
+	switch o := obj.(type) {
+	case *sourcev1.GitRepository:
+		return o.Status.LastHandledReconcileAt
+	case *sourcev1.HelmRepository:
+		return o.Status.LastHandledReconcileAt
+	case *helmv2.HelmRelease:
+		return o.Status.LastHandledReconcileAt
+	case *kustomizev1.Kustomization:
+		return o.Status.LastHandledReconcileAt
+	}
+	return ""
+}
+
+func isSuspended(obj client.Object) bool {
+	switch o := obj.(type) {
+	case *helmv2.HelmRelease:
+		return o.Spec.Suspend
+	case *kustomizev1.Kustomization:
+		return o.Spec.Suspend
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package subscription_test
+
+import (
+	"context"
+	"time"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/pctl/pkg/subscription"
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Reconcile", func() {
+	var (
+		sm         *subscription.Manager
+		fakeClient client.Client
+		sub1       = "sub1"
+		namespace1 = "namespace1"
+		// handle marks the reconcile request as handled like the flux controllers do
+		handle = func(requestedAt string, ready metav1.ConditionStatus) {
+			repo := &sourcev1.GitRepository{}
+			Expect(fakeClient.Get(context.TODO(), client.ObjectKey{Name: "sub1-repo-main", Namespace: namespace1}, repo)).To(Succeed())
+			repo.Status.LastHandledReconcileAt = requestedAt
+			repo.Status.Artifact = &sourcev1.Artifact{Revision: "main/def"}
+			repo.Status.Conditions = []metav1.Condition{{Type: "Ready", Status: "True", Message: "Fetched revision: main/def", LastTransitionTime: metav1.Now()}}
+			Expect(fakeClient.Status().Update(context.TODO(), repo)).To(Succeed())
+
+			release := &helmv2.HelmRelease{}
+			Expect(fakeClient.Get(context.TODO(), client.ObjectKey{Name: "sub1-nginx-server", Namespace: namespace1}, release)).To(Succeed())
+			release.Status.LastHandledReconcileAt = requestedAt
+			release.Status.Conditions = []metav1.Condition{{Type: "Ready", Status: ready, Message: "upgrade failed", LastTransitionTime: metav1.Now()}}
+			Expect(fakeClient.Status().Update(context.TODO(), release)).To(Succeed())
+		}
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(profilesv1.AddToScheme(scheme)).To(Succeed())
+		Expect(sourcev1.AddToScheme(scheme)).To(Succeed())
+		Expect(helmv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kustomizev1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&profilesv1.ProfileSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: sub1, Namespace: namespace1},
+				Spec:       profilesv1.ProfileSubscriptionSpec{ProfileURL: "https://github.com/org/repo", Branch: "main", Path: "nginx"},
+			},
+			&sourcev1.GitRepository{ObjectMeta: metav1.ObjectMeta{Name: "sub1-repo-main", Namespace: namespace1}},
+			&helmv2.HelmRelease{ObjectMeta: metav1.ObjectMeta{Name: "sub1-nginx-server", Namespace: namespace1}},
+		).Build()
+		sm = subscription.NewManager(fakeClient)
+	})
+
+	It("requests the reconciliation of the subscription and its resources", func() {
+		requestedAt, err := sm.Reconcile(namespace1, sub1)
+		Expect(err).NotTo(HaveOccurred())
+		objects := map[string]client.Object{
+			sub1:                &profilesv1.ProfileSubscription{},
+			"sub1-repo-main":    &sourcev1.GitRepository{},
+			"sub1-nginx-server": &helmv2.HelmRelease{},
+		}
+		for name, obj := range objects {
+			Expect(fakeClient.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: namespace1}, obj)).To(Succeed())
+			Expect(obj.GetAnnotations()).To(HaveKeyWithValue("reconcile.fluxcd.io/requestedAt", requestedAt))
+		}
+	})
+
+	It("waits for the resources to handle the request", func() {
+		requestedAt, err := sm.Reconcile(namespace1, sub1)
+		Expect(err).NotTo(HaveOccurred())
+		handle(requestedAt, "True")
+		status, err := sm.WaitForReconcile(namespace1, sub1, requestedAt, time.Millisecond, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Name).To(Equal(sub1))
+		Expect(status.Resources).To(HaveLen(2))
+		Expect(status.Resources[0].Revision).To(Equal("main/def"))
+	})
+
+	It("returns an error if a resource isn't ready after it was reconciled", func() {
+		requestedAt, err := sm.Reconcile(namespace1, sub1)
+		Expect(err).NotTo(HaveOccurred())
+		handle(requestedAt, "False")
+		status, err := sm.WaitForReconcile(namespace1, sub1, requestedAt, time.Millisecond, time.Second)
+		Expect(err).To(MatchError("reconciliation failed: HelmRelease/sub1-nginx-server: upgrade failed"))
+		Expect(status.Resources).To(HaveLen(2))
+		Expect(status.Resources[1].Ready).To(Equal("False"))
+	})
+
+	When("the subscription is suspended", func() {
+		BeforeEach(func() {
+			_, err := sm.Suspend(namespace1, sub1)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns an error without waiting for the suspended resources", func() {
+			requestedAt, err := sm.Reconcile(namespace1, sub1)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = sm.WaitForReconcile(namespace1, sub1, requestedAt, time.Millisecond, time.Hour)
+			Expect(err).To(MatchError("subscription is suspended: HelmRelease/sub1-nginx-server"))
+		})
+	})
+
+	It("returns the resources which didn't handle the request in time", func() {
+		requestedAt, err := sm.Reconcile(namespace1, sub1)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = sm.WaitForReconcile(namespace1, sub1, requestedAt, time.Millisecond, 10*time.Millisecond)
+		Expect(err).To(MatchError("timed out waiting for reconciliation of GitRepository/sub1-repo-main, HelmRelease/sub1-nginx-server"))
+	})
+})
@@ -0,0 +1,184 @@
+package subscription
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SubscriptionStatus contains a summary of a subscription and the status of the resources it created
+type SubscriptionStatus struct {
+	SubscriptionSummary
+	Resources []ResourceStatus `json:"resources"`
+}
+
+// ResourceStatus contains the status of a flux resource created by a subscription
+type ResourceStatus struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Ready     string `json:"ready"`
+	Revision  string `json:"revision"`
+	Message   string `json:"message"`
+}
+
+// Status returns the status of a subscription and of the GitRepositories, HelmRepositories, HelmReleases and
+// Kustomizations it created.
+func (sm *Manager) Status(namespace, name string) (SubscriptionStatus, error) {
+	sub, err := sm.getSubscription(namespace, name)
+	if err != nil {
+		return SubscriptionStatus{}, err
+	}
+	children, err := sm.children(sub)
+	if err != nil {
+		return SubscriptionStatus{}, err
+	}
+	status := SubscriptionStatus{SubscriptionSummary: newSummary(*sub)}
+	for _, child := range children {
+		status.Resources = append(status.Resources, resourceStatus(child))
+	}
+	return status, nil
+}
+
+func (sm *Manager) getSubscription(namespace, name string) (*profilesv1.ProfileSubscription, error) {
+	sub := &profilesv1.ProfileSubscription{}
+	if err := sm.kClient.Get(sm.ctx, client.ObjectKey{Name: name, Namespace: namespace}, sub); err != nil {
+		return nil, fmt.Errorf("failed to get profile subscriptions: %w", err)
+	}
+	return sub, nil
+}
+
+// children returns the flux resources created for a subscription, grouped by kind with sources first. These
+// are found by their owner reference to the subscription or, for ones created without one, by the
+// name prefix the profiles controller gives them.
+func (sm *Manager) children(sub *profilesv1.ProfileSubscription) ([]client.Object, error) {
+	var (
+		gitRepos       sourcev1.GitRepositoryList
+		helmRepos      sourcev1.HelmRepositoryList
+		helmReleases   helmv2.HelmReleaseList
+		kustomizations kustomizev1.KustomizationList
+	)
+	for _, list := range []client.ObjectList{&gitRepos, &helmRepos, &helmReleases, &kustomizations} {
+		if err := sm.kClient.List(sm.ctx, list, client.InNamespace(sub.Namespace)); err != nil {
+			// flux may not be installed, in which case the subscription can't have created anything
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list resources of subscription: %w", err)
+		}
+	}
+
+	var children []client.Object
+	for i := range gitRepos.Items {
+		children = append(children, &gitRepos.Items[i])
+	}
+	for i := range helmRepos.Items {
+		children = append(children, &helmRepos.Items[i])
+	}
+	for i := range helmReleases.Items {
+		children = append(children, &helmReleases.Items[i])
+	}
+	for i := range kustomizations.Items {
+		children = append(children, &kustomizations.Items[i])
+	}
+	owned := children[:0]
+	for _, child := range children {
+		if ownedBy(child, sub) {
+			owned = append(owned, child)
+		}
+	}
+	sort.SliceStable(owned, func(i, j int) bool {
+		if ki, kj := kindOrder[kindOf(owned[i])], kindOrder[kindOf(owned[j])]; ki != kj {
+			return ki < kj
+		}
+		return owned[i].GetName() < owned[j].GetName()
+	})
+	return owned, nil
+}
+
+// kindOrder orders resources of a subscription so sources come before the resources using them.
+var kindOrder = map[string]int{
+	sourcev1.GitRepositoryKind:    0,
+	sourcev1.HelmRepositoryKind:   1,
+	helmv2.HelmReleaseKind:        2,
+	kustomizev1.KustomizationKind: 3,
+}
+
+// ownedBy returns whether obj was created for the subscription. Artifacts are named
+// <subscription>-<profile>-<artifact>, git and helm repositories <subscription>-<repo>-<ref>. Matching the
+// whole prefix keeps a subscription from claiming the resources of another one whose name it prefixes.
+func ownedBy(obj client.Object, sub *profilesv1.ProfileSubscription) bool {
+	if ref := metav1.GetControllerOf(obj); ref != nil {
+		return ref.UID == sub.UID
+	}
+	profile, _ := profileVersion(sub.Spec)
+	repoParts := strings.Split(sub.Spec.ProfileURL, "/")
+	repo := repoParts[len(repoParts)-1]
+	for _, prefix := range []string{profile, repo} {
+		if prefix != "" && strings.HasPrefix(obj.GetName(), sub.Name+"-"+prefix+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// kindOf returns the kind of a subscription or of a flux resource created for it.
+func kindOf(obj client.Object) string {
+	switch obj.(type) {
+	case *profilesv1.ProfileSubscription:
+		return "ProfileSubscription"
+	case *sourcev1.GitRepository:
+		return sourcev1.GitRepositoryKind
+	case *sourcev1.HelmRepository:
+		return sourcev1.HelmRepositoryKind
+	case *helmv2.HelmRelease:
+		return helmv2.HelmReleaseKind
+	case *kustomizev1.Kustomization:
+		return kustomizev1.KustomizationKind
+	}
+	return obj.GetObjectKind().GroupVersionKind().Kind
+}
+
+func resourceStatus(obj client.Object) ResourceStatus {
+	var (
+		conditions []metav1.Condition
+		revision   string
+	)
+	switch o := obj.(type) {
+	case *sourcev1.GitRepository:
+		conditions, revision = o.Status.Conditions, artifactRevision(o.Status.Artifact)
+	case *sourcev1.HelmRepository:
+		conditions, revision = o.Status.Conditions, artifactRevision(o.Status.Artifact)
+	case *helmv2.HelmRelease:
+		conditions, revision = o.Status.Conditions, o.Status.LastAppliedRevision
+	case *kustomizev1.Kustomization:
+		conditions, revision = o.Status.Conditions, o.Status.LastAppliedRevision
+	}
+	ready, message := readyCondition(conditions)
+	if revision == "" {
+		revision = "-"
+	}
+	return ResourceStatus{
+		Kind:      kindOf(obj),
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		Ready:     ready,
+		Revision:  revision,
+		Message:   message,
+	}
+}
+
+func artifactRevision(artifact *sourcev1.Artifact) string {
+	if artifact == nil {
+		return ""
+	}
+	return artifact.Revision
+}
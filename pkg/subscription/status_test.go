@@ -0,0 +1,137 @@
+package subscription_test
+
+import (
+	"context"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/pctl/pkg/subscription"
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Status", func() {
+	var (
+		sm         *subscription.Manager
+		fakeClient client.Client
+		sub1       = "sub1"
+		namespace1 = "namespace1"
+		controller = true
+		ownerRef   = metav1.OwnerReference{
+			APIVersion: "weave.works/v1alpha1",
+			Kind:       "ProfileSubscription",
+			Name:       sub1,
+			UID:        types.UID("sub1-uid"),
+			Controller: &controller,
+		}
+		ready = func(status metav1.ConditionStatus, message string) []metav1.Condition {
+			return []metav1.Condition{{Type: "Ready", Status: status, Message: message, LastTransitionTime: metav1.Now()}}
+		}
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(profilesv1.AddToScheme(scheme)).To(Succeed())
+		Expect(sourcev1.AddToScheme(scheme)).To(Succeed())
+		Expect(helmv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kustomizev1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&profilesv1.ProfileSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: sub1, Namespace: namespace1, UID: ownerRef.UID},
+				Spec:       profilesv1.ProfileSubscriptionSpec{ProfileURL: "https://github.com/org/repo", Branch: "main", Path: "nginx"},
+				Status:     profilesv1.ProfileSubscriptionStatus{Conditions: ready("True", "")},
+			},
+			&sourcev1.GitRepository{
+				ObjectMeta: metav1.ObjectMeta{Name: "sub1-repo-main", Namespace: namespace1, OwnerReferences: []metav1.OwnerReference{ownerRef}},
+				Status: sourcev1.GitRepositoryStatus{
+					Conditions: ready("True", "Fetched revision: main/abc"),
+					Artifact:   &sourcev1.Artifact{Revision: "main/abc"},
+				},
+			},
+			&helmv2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{Name: "sub1-nginx-server", Namespace: namespace1},
+				Status: helmv2.HelmReleaseStatus{
+					Conditions:          ready("False", "install retries exhausted"),
+					LastAppliedRevision: "0.1.0",
+				},
+			},
+			&kustomizev1.Kustomization{
+				ObjectMeta: metav1.ObjectMeta{Name: "sub1-nginx-deployment", Namespace: namespace1, OwnerReferences: []metav1.OwnerReference{ownerRef}},
+			},
+			// owned by another subscription which shares the name prefix
+			&kustomizev1.Kustomization{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "sub1-other-deployment",
+					Namespace:       namespace1,
+					OwnerReferences: []metav1.OwnerReference{{APIVersion: ownerRef.APIVersion, Kind: ownerRef.Kind, Name: "sub1-other", UID: "other-uid", Controller: &controller}},
+				},
+			},
+			&sourcev1.HelmRepository{
+				ObjectMeta: metav1.ObjectMeta{Name: "sub2-repo-chart", Namespace: namespace1},
+			},
+		).Build()
+		sm = subscription.NewManager(fakeClient)
+	})
+
+	It("returns the subscription with the resources it created", func() {
+		status, err := sm.Status(namespace1, sub1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.SubscriptionSummary).To(Equal(subscription.SubscriptionSummary{
+			Name:       sub1,
+			Namespace:  namespace1,
+			Ready:      "True",
+			Profile:    "nginx",
+			ProfileURL: "https://github.com/org/repo",
+			Version:    "main",
+		}))
+		Expect(status.Resources).To(Equal([]subscription.ResourceStatus{
+			{Kind: "GitRepository", Name: "sub1-repo-main", Namespace: namespace1, Ready: "True", Revision: "main/abc", Message: "Fetched revision: main/abc"},
+			{Kind: "HelmRelease", Name: "sub1-nginx-server", Namespace: namespace1, Ready: "False", Revision: "0.1.0", Message: "install retries exhausted"},
+			{Kind: "Kustomization", Name: "sub1-nginx-deployment", Namespace: namespace1, Ready: "Unknown", Revision: "-", Message: "-"},
+		}))
+	})
+
+	When("the name of another subscription starts with the name of the subscription", func() {
+		BeforeEach(func() {
+			spec := profilesv1.ProfileSubscriptionSpec{ProfileURL: "https://github.com/org/repo", Branch: "main", Path: "nginx"}
+			for _, obj := range []client.Object{
+				&profilesv1.ProfileSubscription{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: namespace1}, Spec: spec},
+				&profilesv1.ProfileSubscription{ObjectMeta: metav1.ObjectMeta{Name: "app-staging", Namespace: namespace1}, Spec: spec},
+				&sourcev1.GitRepository{ObjectMeta: metav1.ObjectMeta{Name: "app-repo-main", Namespace: namespace1}},
+				&sourcev1.GitRepository{ObjectMeta: metav1.ObjectMeta{Name: "app-staging-repo-main", Namespace: namespace1}},
+				&helmv2.HelmRelease{ObjectMeta: metav1.ObjectMeta{Name: "app-nginx-server", Namespace: namespace1}},
+				&helmv2.HelmRelease{ObjectMeta: metav1.ObjectMeta{Name: "app-staging-nginx-server", Namespace: namespace1}},
+			} {
+				Expect(fakeClient.Create(context.TODO(), obj)).To(Succeed())
+			}
+		})
+
+		It("only returns the resources of each subscription", func() {
+			resourceNames := func(name string) []string {
+				status, err := sm.Status(namespace1, name)
+				Expect(err).NotTo(HaveOccurred())
+				var names []string
+				for _, r := range status.Resources {
+					names = append(names, r.Name)
+				}
+				return names
+			}
+			Expect(resourceNames("app")).To(Equal([]string{"app-repo-main", "app-nginx-server"}))
+			Expect(resourceNames("app-staging")).To(Equal([]string{"app-staging-repo-main", "app-staging-nginx-server"}))
+		})
+	})
+
+	When("the subscription doesn't exist", func() {
+		It("returns an error", func() {
+			_, err := sm.Status(namespace1, "sub3")
+			Expect(err).To(MatchError(ContainSubstring("failed to get profile subscriptions:")))
+		})
+	})
+})
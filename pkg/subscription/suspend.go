@@ -0,0 +1,51 @@
+package subscription
+
+import (
+	"fmt"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Suspend suspends the HelmReleases and Kustomizations of a subscription, so flux stops applying changes to them.
+// It returns the resources which were suspended.
+func (sm *Manager) Suspend(namespace, name string) ([]string, error) {
+	return sm.setSuspend(namespace, name, true)
+}
+
+// Resume resumes the HelmReleases and Kustomizations of a subscription. It returns the resources which were resumed.
+func (sm *Manager) Resume(namespace, name string) ([]string, error) {
+	return sm.setSuspend(namespace, name, false)
+}
+
+func (sm *Manager) setSuspend(namespace, name string, suspend bool) ([]string, error) {
+	sub, err := sm.getSubscription(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	children, err := sm.children(sub)
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for _, child := range children {
+		patch := client.MergeFrom(child.DeepCopyObject().(client.Object))
+		switch o := child.(type) {
+		case *helmv2.HelmRelease:
+			o.Spec.Suspend = suspend
+		case *kustomizev1.Kustomization:
+			o.Spec.Suspend = suspend
+		default:
+			continue
+		}
+		if err := sm.kClient.Patch(sm.ctx, child, patch); err != nil {
+			return changed, fmt.Errorf("failed to patch %s/%s: %w", kindOf(child), child.GetName(), err)
+		}
+		changed = append(changed, kindOf(child)+"/"+child.GetName())
+	}
+	if len(changed) == 0 {
+		return nil, fmt.Errorf("no HelmReleases or Kustomizations found for subscription %s/%s", namespace, name)
+	}
+	return changed, nil
+}
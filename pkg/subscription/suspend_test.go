@@ -0,0 +1,78 @@
+package subscription_test
+
+import (
+	"context"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/pctl/pkg/subscription"
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Suspend", func() {
+	var (
+		sm         *subscription.Manager
+		fakeClient client.Client
+		sub1       = "sub1"
+		namespace1 = "namespace1"
+		suspended  = func() (bool, bool) {
+			release := &helmv2.HelmRelease{}
+			Expect(fakeClient.Get(context.TODO(), client.ObjectKey{Name: "sub1-nginx-server", Namespace: namespace1}, release)).To(Succeed())
+			kustomization := &kustomizev1.Kustomization{}
+			Expect(fakeClient.Get(context.TODO(), client.ObjectKey{Name: "sub1-nginx-deployment", Namespace: namespace1}, kustomization)).To(Succeed())
+			return release.Spec.Suspend, kustomization.Spec.Suspend
+		}
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(profilesv1.AddToScheme(scheme)).To(Succeed())
+		Expect(sourcev1.AddToScheme(scheme)).To(Succeed())
+		Expect(helmv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kustomizev1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&profilesv1.ProfileSubscription{
+				ObjectMeta: metav1.ObjectMeta{Name: sub1, Namespace: namespace1},
+				Spec:       profilesv1.ProfileSubscriptionSpec{ProfileURL: "https://github.com/org/repo", Branch: "main", Path: "nginx"},
+			},
+			&sourcev1.GitRepository{ObjectMeta: metav1.ObjectMeta{Name: "sub1-repo-main", Namespace: namespace1}},
+			&helmv2.HelmRelease{ObjectMeta: metav1.ObjectMeta{Name: "sub1-nginx-server", Namespace: namespace1}},
+			&kustomizev1.Kustomization{ObjectMeta: metav1.ObjectMeta{Name: "sub1-nginx-deployment", Namespace: namespace1}},
+		).Build()
+		sm = subscription.NewManager(fakeClient)
+	})
+
+	It("suspends and resumes the releases and kustomizations of the subscription", func() {
+		changed, err := sm.Suspend(namespace1, sub1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(Equal([]string{"HelmRelease/sub1-nginx-server", "Kustomization/sub1-nginx-deployment"}))
+		release, kustomization := suspended()
+		Expect(release).To(BeTrue())
+		Expect(kustomization).To(BeTrue())
+
+		changed, err = sm.Resume(namespace1, sub1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(HaveLen(2))
+		release, kustomization = suspended()
+		Expect(release).To(BeFalse())
+		Expect(kustomization).To(BeFalse())
+	})
+
+	When("the subscription has no releases or kustomizations", func() {
+		BeforeEach(func() {
+			Expect(fakeClient.Create(context.TODO(), &profilesv1.ProfileSubscription{ObjectMeta: metav1.ObjectMeta{Name: "sub2", Namespace: namespace1}})).To(Succeed())
+		})
+
+		It("returns an error", func() {
+			_, err := sm.Suspend(namespace1, "sub2")
+			Expect(err).To(MatchError("no HelmReleases or Kustomizations found for subscription namespace1/sub2"))
+		})
+	})
+})
@@ -0,0 +1,157 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// subscriptionResource is the resource profile subscriptions are served as
+var subscriptionResource = profilesv1.GroupVersion.WithResource("profilesubscriptions")
+
+// Watcher watches profile subscriptions for changes
+type Watcher struct {
+	dClient dynamic.Interface
+}
+
+// NewWatcher returns a Watcher
+func NewWatcher(dClient dynamic.Interface) *Watcher {
+	return &Watcher{
+		dClient: dClient,
+	}
+}
+
+// Watch calls onChange with the subscriptions selected by opts, and again every time one of them changes. It
+// returns once onChange returns true or an error, or ctx is done. The subscriptions are watched with an informer,
+// which lists and watches them again whenever the API server ends the watch.
+func (w *Watcher) Watch(ctx context.Context, opts ListOptions, onChange func([]SubscriptionSummary) (bool, error)) error {
+	sel, err := opts.selector()
+	if err != nil {
+		return err
+	}
+	resource := w.dClient.Resource(subscriptionResource).Namespace(opts.Namespace)
+	// the informer retries failing lists until ctx is done, so errors such as missing permissions are returned
+	// right away instead
+	if _, err := resource.List(ctx, metav1.ListOptions{LabelSelector: opts.LabelSelector, FieldSelector: opts.FieldSelector, Limit: 1}); err != nil {
+		return fmt.Errorf("failed to list profile subscriptions: %w", err)
+	}
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector, options.FieldSelector = opts.LabelSelector, opts.FieldSelector
+			return resource.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector, options.FieldSelector = opts.LabelSelector, opts.FieldSelector
+			return resource.Watch(ctx, options)
+		},
+	}
+
+	var (
+		store   cache.Store
+		last    []SubscriptionSummary
+		started bool
+	)
+	// changed calls onChange with the subscriptions in the store of the informer if they changed since last time
+	changed := func() (bool, error) {
+		summaries := map[types.NamespacedName]SubscriptionSummary{}
+		for _, obj := range store.List() {
+			if err := update(summaries, obj.(runtime.Object), sel); err != nil {
+				return false, err
+			}
+		}
+		sorted := sortedSummaries(summaries)
+		if started && reflect.DeepEqual(sorted, last) {
+			return false, nil
+		}
+		started, last = true, sorted
+		return onChange(sorted)
+	}
+	_, err = watchtools.UntilWithSync(ctx, lw, &unstructured.Unstructured{}, func(s cache.Store) (bool, error) {
+		store = s
+		return changed()
+	}, func(watch.Event) (bool, error) {
+		return changed()
+	})
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// WaitForReady watches subscriptions like Watch until all of them are Ready, calling onChange with every change.
+// If ctx is done first, the error lists the subscriptions which aren't ready.
+func (w *Watcher) WaitForReady(ctx context.Context, opts ListOptions, onChange func([]SubscriptionSummary)) error {
+	var last []SubscriptionSummary
+	err := w.Watch(ctx, opts, func(summaries []SubscriptionSummary) (bool, error) {
+		last = summaries
+		onChange(summaries)
+		if len(summaries) == 0 {
+			return false, nil
+		}
+		for _, s := range summaries {
+			if s.Ready != string(metav1.ConditionTrue) {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if len(last) == 0 {
+		return errors.New("timed out waiting for profile subscriptions to be ready: no subscriptions found")
+	}
+	var pending []string
+	for _, s := range last {
+		if s.Ready != string(metav1.ConditionTrue) {
+			pending = append(pending, fmt.Sprintf("%s/%s (%s)", s.Namespace, s.Name, s.Message))
+		}
+	}
+	return fmt.Errorf("timed out waiting for profile subscriptions to be ready: %s", strings.Join(pending, ", "))
+}
+
+// update sets the summary of a subscription in summaries if it's selected, or removes it if it no longer is.
+func update(summaries map[types.NamespacedName]SubscriptionSummary, obj runtime.Object, sel *selector) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected object %T in watch of profile subscriptions", obj)
+	}
+	var sub profilesv1.ProfileSubscription
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &sub); err != nil {
+		return fmt.Errorf("failed to decode profile subscription %s: %w", u.GetName(), err)
+	}
+	key := types.NamespacedName{Namespace: sub.Namespace, Name: sub.Name}
+	if summary := newSummary(sub); sel.matches(sub, summary) {
+		summaries[key] = summary
+	} else {
+		delete(summaries, key)
+	}
+	return nil
+}
+
+func sortedSummaries(summaries map[types.NamespacedName]SubscriptionSummary) []SubscriptionSummary {
+	sorted := make([]SubscriptionSummary, 0, len(summaries))
+	for _, s := range summaries {
+		sorted = append(sorted, s)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
@@ -0,0 +1,142 @@
+package subscription_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/pctl/pkg/subscription"
+	profilesv1 "github.com/weaveworks/profiles/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Watch", func() {
+	var (
+		watcher     *subscription.Watcher
+		fakeWatcher *watch.FakeWatcher
+		namespace1  = "namespace1"
+		newSub      = func(name string, ready metav1.ConditionStatus, message string) *unstructured.Unstructured {
+			sub := &profilesv1.ProfileSubscription{
+				TypeMeta:   metav1.TypeMeta{Kind: "ProfileSubscription", APIVersion: "weave.works/v1alpha1"},
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace1},
+			}
+			if ready != "" {
+				sub.Status.Conditions = []metav1.Condition{{Type: "Ready", Status: ready, Message: message}}
+			}
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(sub)
+			Expect(err).NotTo(HaveOccurred())
+			return &unstructured.Unstructured{Object: content}
+		}
+	)
+
+	BeforeEach(func() {
+		dClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{profilesv1.GroupVersion.WithResource("profilesubscriptions"): "ProfileSubscriptionList"},
+			newSub("sub1", "False", "reconciling"),
+			newSub("sub2", "", ""),
+		)
+		fakeWatcher = watch.NewFake()
+		dClient.PrependWatchReactor("profilesubscriptions", k8stesting.DefaultWatchReactor(fakeWatcher, nil))
+		watcher = subscription.NewWatcher(dClient)
+	})
+
+	It("calls onChange with the subscriptions every time they change", func() {
+		changes := make(chan []subscription.SubscriptionSummary)
+		errs := make(chan error, 1)
+		go func() {
+			defer GinkgoRecover()
+			errs <- watcher.Watch(context.TODO(), subscription.ListOptions{Namespace: namespace1}, func(summaries []subscription.SubscriptionSummary) (bool, error) {
+				changes <- summaries
+				return len(summaries) == 1, nil
+			})
+		}()
+
+		Eventually(changes).Should(Receive(Equal([]subscription.SubscriptionSummary{
+			{Name: "sub1", Namespace: namespace1, Ready: "False", Message: "reconciling"},
+			{Name: "sub2", Namespace: namespace1, Ready: "Unknown", Message: "-"},
+		})))
+		fakeWatcher.Modify(newSub("sub1", "True", "ready"))
+		Eventually(changes).Should(Receive(Equal([]subscription.SubscriptionSummary{
+			{Name: "sub1", Namespace: namespace1, Ready: "True", Message: "ready"},
+			{Name: "sub2", Namespace: namespace1, Ready: "Unknown", Message: "-"},
+		})))
+		fakeWatcher.Delete(newSub("sub2", "", ""))
+		Eventually(changes).Should(Receive(Equal([]subscription.SubscriptionSummary{
+			{Name: "sub1", Namespace: namespace1, Ready: "True", Message: "ready"},
+		})))
+		Eventually(errs).Should(Receive(BeNil()))
+	})
+
+	When("a field selector is given", func() {
+		It("only watches the selected subscriptions", func() {
+			var summaries []subscription.SubscriptionSummary
+			err := watcher.Watch(context.TODO(), subscription.ListOptions{Namespace: namespace1, FieldSelector: "metadata.name=sub2"}, func(s []subscription.SubscriptionSummary) (bool, error) {
+				summaries = s
+				return true, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summaries).To(Equal([]subscription.SubscriptionSummary{
+				{Name: "sub2", Namespace: namespace1, Ready: "Unknown", Message: "-"},
+			}))
+		})
+	})
+
+	Context("WaitForReady", func() {
+		It("returns once the subscription is ready", func() {
+			errs := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errs <- watcher.WaitForReady(context.TODO(), subscription.ListOptions{Namespace: namespace1, FieldSelector: "metadata.name=sub1"}, func([]subscription.SubscriptionSummary) {})
+			}()
+			fakeWatcher.Modify(newSub("sub1", "True", "ready"))
+			Eventually(errs).Should(Receive(BeNil()))
+		})
+
+		When("the API server ends the watch", func() {
+			var watchers chan *watch.FakeWatcher
+
+			BeforeEach(func() {
+				watchers = make(chan *watch.FakeWatcher, 2)
+				dClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+					map[schema.GroupVersionResource]string{profilesv1.GroupVersion.WithResource("profilesubscriptions"): "ProfileSubscriptionList"},
+					newSub("sub1", "False", "reconciling"),
+				)
+				dClient.PrependWatchReactor("profilesubscriptions", func(k8stesting.Action) (bool, watch.Interface, error) {
+					w := watch.NewFake()
+					watchers <- w
+					return true, w, nil
+				})
+				watcher = subscription.NewWatcher(dClient)
+			})
+
+			It("watches again and returns once the subscription is ready", func() {
+				errs := make(chan error, 1)
+				go func() {
+					defer GinkgoRecover()
+					errs <- watcher.WaitForReady(context.TODO(), subscription.ListOptions{Namespace: namespace1}, func([]subscription.SubscriptionSummary) {})
+				}()
+				var first, second *watch.FakeWatcher
+				Eventually(watchers).Should(Receive(&first))
+				first.Stop()
+				Eventually(watchers, 5*time.Second).Should(Receive(&second))
+				Consistently(errs).ShouldNot(Receive())
+				second.Modify(newSub("sub1", "True", "ready"))
+				Eventually(errs).Should(Receive(BeNil()))
+			})
+		})
+
+		It("returns the subscriptions which aren't ready after the timeout", func() {
+			ctx, cancel := context.WithTimeout(context.TODO(), 500*time.Millisecond)
+			defer cancel()
+			err := watcher.WaitForReady(ctx, subscription.ListOptions{Namespace: namespace1}, func([]subscription.SubscriptionSummary) {})
+			Expect(err).To(MatchError("timed out waiting for profile subscriptions to be ready: namespace1/sub1 (reconciling), namespace1/sub2 (-)"))
+		})
+	})
+})
@@ -429,14 +429,13 @@ status: {}
 				cmd.Dir = temp
 				err := cmd.Run()
 				Expect(err).NotTo(HaveOccurred())
-				filename := filepath.Join(repoLocation, "profile_subscription.yaml")
 				suffix, err := randString(3)
 				Expect(err).NotTo(HaveOccurred())
 				branch := "prtest_" + suffix
 				cmd = exec.Command(binaryPath,
 					"install",
 					"--out",
-					filename,
+					repoLocation,
 					"--create-pr",
 					"--branch",
 					branch,